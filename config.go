@@ -0,0 +1,471 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/urfave/cli"
+)
+
+// projectFile is read from working directory unless --config is given
+const projectFile = "snowboard.toml"
+
+// globalFlags are options shared by every command
+var globalFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "config",
+		Value: projectFile,
+		Usage: "Project configuration file",
+	},
+	cli.StringFlag{
+		Name:  "seed",
+		Usage: "Seed file replacing the one referred by API blueprint",
+	},
+	cli.StringFlag{
+		Name:  "base-dir",
+		Usage: "Directory partials and seed are resolved from, defaults to directory of API blueprint or working directory for standard input",
+	},
+}
+
+// project is the content of project configuration. Tables hold options of
+// commands they are named after, nested tables those of subcommands, using
+// long flag names as keys. Top level keys are global options, which command
+// tables may override.
+type project map[string]interface{}
+
+func loadProject(c *cli.Context, cmds []cli.Command) (project, error) {
+	name := c.GlobalString("config")
+	p := project{}
+
+	if _, err := os.Stat(name); os.IsNotExist(err) && !c.GlobalIsSet("config") {
+		return p, nil
+	}
+
+	if _, err := toml.DecodeFile(name, &p); err != nil {
+		return nil, fmt.Errorf("Unable to read project config %s: %s", name, err)
+	}
+
+	if err := p.check(globalFlags, cmds, nil); err != nil {
+		return nil, fmt.Errorf("Unable to read project config %s: %s", name, err)
+	}
+
+	p.resolve(filepath.Dir(name), globalFlags, cmds)
+
+	return p, nil
+}
+
+// check reports options which aren't flags of command at path, or tables
+// which aren't its subcommands
+func (p project) check(flags []cli.Flag, cmds []cli.Command, path []string) error {
+	for _, k := range p.keys() {
+		section, ok := p[k].(map[string]interface{})
+		if !ok {
+			if findFlag(flags, k) != nil || findFlag(globalFlags, k) != nil {
+				continue
+			}
+
+			if len(path) == 0 {
+				return fmt.Errorf("unknown option %q", k)
+			}
+
+			return fmt.Errorf("unknown option %q of [%s]", k, strings.Join(path, "."))
+		}
+
+		sub := append(append([]string{}, path...), k)
+
+		cmd := findCommand(cmds, k)
+		if cmd == nil {
+			return fmt.Errorf("unknown command [%s]", strings.Join(sub, "."))
+		}
+
+		if err := project(section).check(cmd.Flags, cmd.Subcommands, sub); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pathOptions are options naming files or directories
+var pathOptions = map[string]bool{
+	"input":    true,
+	"output":   true,
+	"seed":     true,
+	"base-dir": true,
+	"manifest": true,
+	"config":   true,
+	"journal":  true,
+	"tls-cert": true,
+	"tls-key":  true,
+}
+
+// templateOptions name either builtin template or template file, they are
+// resolved only when the value looks like a path
+var templateOptions = map[string]bool{
+	"template": true,
+}
+
+// resolve makes relative paths of options relative to dir, the directory of
+// project configuration, as inputs of manifest are
+func (p project) resolve(dir string, flags []cli.Flag, cmds []cli.Command) {
+	for _, k := range p.keys() {
+		if section, ok := p[k].(map[string]interface{}); ok {
+			if cmd := findCommand(cmds, k); cmd != nil {
+				project(section).resolve(dir, cmd.Flags, cmd.Subcommands)
+			}
+
+			continue
+		}
+
+		f := findFlag(flags, k)
+		if f == nil {
+			f = findFlag(globalFlags, k)
+		}
+
+		if f == nil {
+			continue
+		}
+
+		if s, ok := p[k].(string); ok && hasOption(f, templateOptions) && isTemplatePath(s) {
+			p[k] = resolvePath(dir, s)
+			continue
+		}
+
+		if !hasOption(f, pathOptions) {
+			continue
+		}
+
+		switch x := p[k].(type) {
+		case string:
+			p[k] = resolvePath(dir, x)
+		case []interface{}:
+			for i, y := range x {
+				if s, ok := y.(string); ok {
+					x[i] = resolvePath(dir, s)
+				}
+			}
+		}
+	}
+}
+
+// hasOption reports whether any name of f is among options
+func hasOption(f cli.Flag, options map[string]bool) bool {
+	for _, name := range flagNames(f) {
+		if options[name] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isTemplatePath reports whether template names a file rather than builtin
+// template such as "alpha"
+func isTemplatePath(template string) bool {
+	return strings.ContainsRune(template, '/') || strings.ContainsRune(template, filepath.Separator) || strings.HasSuffix(template, ".html")
+}
+
+func resolvePath(dir, name string) string {
+	if name == "" || name == stdio || filepath.IsAbs(name) {
+		return name
+	}
+
+	return filepath.Join(dir, name)
+}
+
+// section returns options of command at path
+func (p project) section(path []string) project {
+	s := p
+
+	for _, name := range path {
+		x, ok := s[name].(map[string]interface{})
+		if !ok {
+			return project{}
+		}
+
+		s = x
+	}
+
+	return s
+}
+
+func (p project) keys() []string {
+	ks := []string{}
+
+	for k := range p {
+		ks = append(ks, k)
+	}
+
+	sort.Strings(ks)
+
+	return ks
+}
+
+// configure makes every command read options missing from command line from
+// project configuration
+func configure(app *cli.App) {
+	app.Flags = append(app.Flags, globalFlags...)
+
+	for i := range app.Commands {
+		configureCommand(&app.Commands[i], app.Commands, nil)
+	}
+}
+
+func configureCommand(cmd *cli.Command, root []cli.Command, parent []string) {
+	path := append(append([]string{}, parent...), cmd.Name)
+
+	for i := range cmd.Subcommands {
+		configureCommand(&cmd.Subcommands[i], root, path)
+	}
+
+	if cmd.Action == nil {
+		return
+	}
+
+	action, flags := cmd.Action, cmd.Flags
+
+	cmd.Action = func(c *cli.Context) error {
+		p, err := loadProject(c, root)
+		if err != nil {
+			return err
+		}
+
+		if err := applyOptions(c, flags, p, p.section(path)); err != nil {
+			return err
+		}
+
+		return cli.HandleAction(action, c)
+	}
+}
+
+// applyOptions sets flags not given on command line from section, then
+// global flags from section or top level of project
+func applyOptions(c *cli.Context, flags []cli.Flag, p, section project) error {
+	for _, k := range section.keys() {
+		f := findFlag(flags, k)
+		if f == nil {
+			continue
+		}
+
+		if err := setFlag(c.IsSet, c.Set, f, section[k]); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range globalFlags {
+		for _, name := range flagNames(f) {
+			v, ok := section[name]
+			if !ok {
+				v, ok = p[name]
+			}
+
+			if !ok {
+				continue
+			}
+
+			if err := setFlag(c.GlobalIsSet, c.GlobalSet, f, v); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// setFlag sets every name of flag to option value v, unless any of them is
+// given on command line. Repeated flags take lists, their names share values.
+func setFlag(isSet func(string) bool, set func(string, string) error, f cli.Flag, v interface{}) error {
+	names := flagNames(f)
+
+	for _, name := range names {
+		if isSet(name) {
+			return nil
+		}
+	}
+
+	vs := []string{}
+
+	switch x := v.(type) {
+	case map[string]interface{}:
+		return nil
+	case []interface{}:
+		for _, y := range x {
+			vs = append(vs, fmt.Sprint(y))
+		}
+	default:
+		vs = append(vs, fmt.Sprint(x))
+	}
+
+	switch f.(type) {
+	case cli.StringSliceFlag, cli.IntSliceFlag, cli.Int64SliceFlag:
+		names = names[:1]
+	default:
+		if len(vs) != 1 {
+			return fmt.Errorf("Unable to set option %q: expected single value, got %d", names[len(names)-1], len(vs))
+		}
+	}
+
+	for _, name := range names {
+		for _, s := range vs {
+			if err := set(name, s); err != nil {
+				return fmt.Errorf("Unable to set option %q: %s", name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func flagNames(f cli.Flag) []string {
+	xs := []string{}
+
+	for _, name := range strings.Split(f.GetName(), ",") {
+		xs = append(xs, strings.TrimSpace(name))
+	}
+
+	return xs
+}
+
+func findFlag(flags []cli.Flag, name string) cli.Flag {
+	for _, f := range flags {
+		for _, x := range flagNames(f) {
+			if x == name {
+				return f
+			}
+		}
+	}
+
+	return nil
+}
+
+func findCommand(cmds []cli.Command, name string) *cli.Command {
+	for i := range cmds {
+		if cmds[i].HasName(name) {
+			return &cmds[i]
+		}
+	}
+
+	return nil
+}
+
+// initProject writes project configuration along with starter blueprint
+// into dir
+func initProject(c *cli.Context, dir string, force bool) error {
+	if dir == "" {
+		dir = "."
+	}
+
+	files := []struct {
+		name string
+		body string
+	}{
+		{projectFile, starterProject},
+		{"API.apib", starterBlueprint},
+	}
+
+	if !force {
+		for _, f := range files {
+			if _, err := os.Stat(filepath.Join(dir, f.name)); err == nil {
+				return fmt.Errorf("Unable to initialize project: %s already exists, use --force to overwrite", filepath.Join(dir, f.name))
+			}
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, f.name), []byte(f.body), 0644); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(c.App.Writer, "Created %s\n", filepath.Join(dir, f.name))
+	}
+
+	return nil
+}
+
+const starterProject = `# Snowboard project configuration. Each table holds options of the command it
+# is named after, using long flag names, e.g. [gen.go-client] for subcommands.
+# Options given on command line take precedence.
+
+# Seed file replacing the one referred by API blueprint
+# seed = "seed.json"
+
+[lint]
+input = "API.apib"
+rule = ["api-host", "action-title", "response-example", "uri-parameter"]
+
+[html]
+input = "API.apib"
+output = "index.html"
+template = "alpha"
+bind = "127.0.0.1:8088"
+console = true
+
+[mock]
+input = "API.apib"
+bind = "127.0.0.1:8087"
+cors = true
+`
+
+const starterBlueprint = `FORMAT: 1A
+HOST: https://api.example.com
+
+# Notes API
+
+Notes API keeps short notes. Preview its documentation with ` + "`snowboard html -s`" + `
+and try it out with ` + "`snowboard mock`" + `.
+
+## Group Notes
+
+## Notes Collection [/notes{?limit}]
+
++ Parameters
+    + limit: 10 (number, optional) - Maximum number of notes returned
+
+### List Notes [GET]
+
++ Response 200 (application/json)
+
+    + Attributes (array[Note])
+
+### Create a Note [POST]
+
++ Request (application/json)
+
+    + Attributes
+        + title: Buy milk (string, required)
+
++ Response 201 (application/json)
+
+    + Attributes (Note)
+
+## Note [/notes/{id}]
+
++ Parameters
+    + id: 1 (number) - ID of the note
+
+### Retrieve a Note [GET]
+
++ Response 200 (application/json)
+
+    + Attributes (Note)
+
+### Delete a Note [DELETE]
+
++ Response 204
+
+# Data Structures
+
+## Note (object)
+
++ id: 1 (number, required)
++ title: Buy milk (string, required)
+`
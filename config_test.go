@@ -0,0 +1,160 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+)
+
+// options are values of flags seen by command action
+type options struct {
+	input    string
+	alias    string
+	rules    []string
+	seed     string
+	output   string
+	template string
+}
+
+func configApp(o *options) *cli.App {
+	app := cli.NewApp()
+	app.Writer = ioutil.Discard
+	app.Commands = []cli.Command{
+		{
+			Name: "lint",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "i, input"},
+				cli.StringSliceFlag{Name: "r, rule"},
+			},
+			Action: func(c *cli.Context) error {
+				o.input, o.alias, o.rules, o.seed = c.String("input"), c.String("i"), c.StringSlice("r"), c.GlobalString("seed")
+				return nil
+			},
+		},
+		{
+			Name:  "html",
+			Flags: []cli.Flag{cli.StringFlag{Name: "t, template"}},
+			Action: func(c *cli.Context) error {
+				o.template = c.String("t")
+				return nil
+			},
+		},
+		{
+			Name: "gen",
+			Subcommands: []cli.Command{
+				{
+					Name:  "go-client",
+					Flags: []cli.Flag{cli.StringFlag{Name: "o, output"}},
+					Action: func(c *cli.Context) error {
+						o.output = c.String("o")
+						return nil
+					},
+				},
+			},
+		},
+	}
+
+	configure(app)
+
+	return app
+}
+
+// runApp runs app with args, returning error instead of exiting
+func runApp(o *options, args ...string) error {
+	exit, errWriter := cli.OsExiter, cli.ErrWriter
+	defer func() { cli.OsExiter, cli.ErrWriter = exit, errWriter }()
+
+	cli.OsExiter, cli.ErrWriter = func(int) {}, ioutil.Discard
+
+	return configApp(o).Run(append([]string{"snowboard"}, args...))
+}
+
+func writeConfig(t *testing.T, body string) (string, string, func()) {
+	dir, err := ioutil.TempDir("", "config")
+	assert.Nil(t, err)
+
+	name := filepath.Join(dir, "other", projectFile)
+	assert.Nil(t, os.MkdirAll(filepath.Dir(name), 0755))
+	assert.Nil(t, ioutil.WriteFile(name, []byte(body), 0644))
+
+	return filepath.Join(dir, "other"), name, func() { os.RemoveAll(dir) }
+}
+
+func TestConfigure(t *testing.T) {
+	dir, name, cleanup := writeConfig(t, `
+seed = "top.json"
+
+[lint]
+i = "API.apib"
+rule = ["api-host", "uri-parameter"]
+seed = "lint.json"
+
+[gen.go-client]
+output = "-"
+`)
+	defer cleanup()
+
+	o := &options{}
+	assert.Nil(t, runApp(o, "--config", name, "lint"))
+	assert.Equal(t, filepath.Join(dir, "API.apib"), o.input)
+	assert.Equal(t, o.input, o.alias)
+	assert.Equal(t, []string{"api-host", "uri-parameter"}, o.rules)
+	assert.Equal(t, filepath.Join(dir, "lint.json"), o.seed)
+
+	o = &options{}
+	assert.Nil(t, runApp(o, "--config", name, "--seed", "cli.json", "lint", "--input", "cli.apib", "-r", "action-title"))
+	assert.Equal(t, "cli.apib", o.input)
+	assert.Equal(t, "cli.apib", o.alias)
+	assert.Equal(t, []string{"action-title"}, o.rules)
+	assert.Equal(t, "cli.json", o.seed)
+
+	o = &options{}
+	assert.Nil(t, runApp(o, "--config", name, "gen", "go-client"))
+	assert.Equal(t, "-", o.output)
+}
+
+func TestConfigure_template(t *testing.T) {
+	for value, resolved := range map[string]bool{
+		"alpha":           false,
+		"theme.html":      true,
+		"themes/dark.tpl": true,
+	} {
+		dir, name, cleanup := writeConfig(t, "[html]\ntemplate = \""+value+"\"\n")
+
+		o := &options{}
+		assert.Nil(t, runApp(o, "--config", name, "html"))
+
+		if resolved {
+			assert.Equal(t, filepath.Join(dir, value), o.template)
+		} else {
+			assert.Equal(t, value, o.template)
+		}
+
+		cleanup()
+	}
+}
+
+func TestConfigure_unknown(t *testing.T) {
+	for body, message := range map[string]string{
+		`colour = true`:                  `unknown option "colour"`,
+		"[lint]\nbind = \":8080\"":       `unknown option "bind" of [lint]`,
+		"[gen.python]\noutput = \"out\"": `unknown command [gen.python]`,
+		"[gen]\nlint = 1":                `unknown option "lint" of [gen]`,
+	} {
+		_, name, cleanup := writeConfig(t, body)
+
+		err := runApp(&options{}, "--config", name, "lint")
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), message)
+		}
+
+		cleanup()
+	}
+
+	err := runApp(&options{}, "--config", "missing.toml", "lint")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,333 @@
+// Package generator generates client and server code from API blueprint
+package generator
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/subosito/snowboard/api"
+)
+
+// Operation is a transition described in terms of code generation
+type Operation struct {
+	Name        string
+	Title       string
+	Description string
+	Method      string
+	Path        string
+	PathParams  []Param
+	QueryParams []Param
+	Request     *Type
+	ContentType string
+	Responses   []Response
+}
+
+// Param is a path or query parameter of operation
+type Param struct {
+	Key         string
+	Name        string
+	Kind        string
+	Required    bool
+	Description string
+}
+
+// Response is a documented response of operation
+type Response struct {
+	StatusCode  int
+	ContentType string
+	Type        *Type
+}
+
+var uriExpressionPattern = regexp.MustCompile(`\{([^}]*)\}`)
+
+// reservedTypes are names generated code declares besides types of bodies,
+// and globals TypeScript client relies on. Body types named alike are suffixed.
+var reservedTypes = []string{
+	"ApiError", "Array", "Client", "DefaultBaseURL", "Error", "Handler", "Headers", "JSON",
+	"New", "NewRouter", "Object", "Param", "Promise", "RequestInit", "Service", "String",
+}
+
+// reservedOperations are names of client members, which operations must not
+// be named after
+var reservedOperations = []string{"BaseURL", "Constructor", "HTTPClient", "Header", "Init", "Request"}
+
+// Operations builds operations from all transitions of blueprint.API struct
+func Operations(b *api.API) []*Operation {
+	ops := []*Operation{}
+	seen := map[string]int{}
+
+	for _, name := range reservedOperations {
+		seen[name] = 1
+	}
+
+	for _, g := range b.ResourceGroups {
+		for _, x := range g.Resources {
+			for _, t := range x.Transitions {
+				op := newOperation(b, t)
+
+				if n := seen[op.Name]; n > 0 {
+					seen[op.Name] = n + 1
+					op.Name = op.Name + strconv.Itoa(n+1)
+				} else {
+					seen[op.Name] = 1
+				}
+
+				op.digTypes(t)
+				ops = append(ops, op)
+			}
+		}
+	}
+
+	nameTypes(ops)
+
+	return ops
+}
+
+func newOperation(b *api.API, t *api.Transition) *Operation {
+	name := t.Title
+	if name == "" {
+		name = t.Permalink
+	}
+
+	op := &Operation{
+		Name:        Identifier(name),
+		Title:       t.Title,
+		Description: t.Description,
+		Method:      t.Method,
+		Path:        operationPath(t.URL, b.Host()),
+	}
+
+	params := map[string]api.Parameter{}
+	for _, p := range t.Href.Parameters {
+		params[p.Key] = p
+	}
+
+	seen := map[string]bool{}
+
+	for _, ms := range uriExpressionPattern.FindAllStringSubmatch(op.Path, -1) {
+		expr := ms[1]
+		query := strings.HasPrefix(expr, "?") || strings.HasPrefix(expr, "&")
+		expr = strings.TrimLeft(expr, "+#./;?&")
+
+		for _, spec := range strings.Split(expr, ",") {
+			key := strings.TrimSuffix(strings.SplitN(spec, ":", 2)[0], "*")
+			if seen[key] {
+				continue
+			}
+
+			seen[key] = true
+			p := params[key]
+
+			x := Param{
+				Key:         key,
+				Name:        Identifier(key),
+				Kind:        p.Kind,
+				Required:    p.Required || !query,
+				Description: p.Description,
+			}
+
+			if query {
+				op.QueryParams = append(op.QueryParams, x)
+			} else {
+				op.PathParams = append(op.PathParams, x)
+			}
+		}
+	}
+
+	return op
+}
+
+func (op *Operation) digTypes(t *api.Transition) {
+	seen := map[int]bool{}
+
+	for _, x := range t.Transactions {
+		if op.Request == nil && (x.Request.Schema.Body != "" || x.Request.Body.Body != "") {
+			op.Request = NewType(op.Name+"Request", x.Request.Schema.Body, x.Request.Body.Body)
+			op.ContentType = x.Request.Body.ContentType
+		}
+
+		if seen[x.Response.StatusCode] {
+			continue
+		}
+
+		seen[x.Response.StatusCode] = true
+
+		r := Response{
+			StatusCode:  x.Response.StatusCode,
+			ContentType: x.Response.Body.ContentType,
+		}
+
+		if x.Response.Schema.Body != "" || x.Response.Body.Body != "" {
+			r.Type = NewType(op.Name+"Response"+strconv.Itoa(r.StatusCode), x.Response.Schema.Body, x.Response.Body.Body)
+		}
+
+		op.Responses = append(op.Responses, r)
+	}
+}
+
+// Success returns the first documented 2xx response
+func (op *Operation) Success() *Response {
+	for i, r := range op.Responses {
+		if r.StatusCode >= 200 && r.StatusCode < 300 {
+			return &op.Responses[i]
+		}
+	}
+
+	return nil
+}
+
+// Failures returns documented non-2xx responses
+func (op *Operation) Failures() []Response {
+	rs := []Response{}
+
+	for _, r := range op.Responses {
+		if r.StatusCode < 200 || r.StatusCode >= 300 {
+			rs = append(rs, r)
+		}
+	}
+
+	return rs
+}
+
+// operationPath strips host from transition URL
+func operationPath(u, host string) string {
+	u = strings.Replace(u, strings.TrimSuffix(host, "/"), "", 1)
+
+	if !strings.HasPrefix(u, "/") {
+		u = "/" + u
+	}
+
+	return u
+}
+
+// pathExpr converts path template into string concatenation expression.
+// Each variable is substituted by value(key, escape), where escape reports
+// whether reserved characters must be percent-encoded. Query expressions are
+// omitted.
+func pathExpr(path string, value func(key string, escape bool) string) string {
+	xs := []string{}
+	last := 0
+
+	for _, loc := range uriExpressionPattern.FindAllStringSubmatchIndex(path, -1) {
+		if loc[0] > last {
+			xs = append(xs, strconv.Quote(path[last:loc[0]]))
+		}
+
+		last = loc[1]
+		expr := path[loc[2]:loc[3]]
+
+		if expr == "" || expr[0] == '?' || expr[0] == '&' {
+			continue
+		}
+
+		op := byte(0)
+		if strings.IndexByte("+#./;", expr[0]) != -1 {
+			op = expr[0]
+			expr = expr[1:]
+		}
+
+		for i, spec := range strings.Split(expr, ",") {
+			key := strings.TrimSuffix(strings.SplitN(spec, ":", 2)[0], "*")
+			prefix := ","
+			escape := true
+
+			switch op {
+			case '+':
+				escape = false
+			case '#':
+				escape = false
+				if i == 0 {
+					prefix = "#"
+				}
+			case '.', '/':
+				prefix = string(op)
+			case ';':
+				prefix = ";" + key + "="
+			}
+
+			if i == 0 && op != '#' && op != '.' && op != '/' && op != ';' {
+				prefix = ""
+			}
+
+			if prefix != "" {
+				xs = append(xs, strconv.Quote(prefix))
+			}
+
+			xs = append(xs, value(key, escape))
+		}
+	}
+
+	if last < len(path) {
+		xs = append(xs, strconv.Quote(path[last:]))
+	}
+
+	if len(xs) == 0 {
+		return `""`
+	}
+
+	return strings.Join(xs, " + ")
+}
+
+var initialisms = map[string]string{
+	"api":  "API",
+	"html": "HTML",
+	"http": "HTTP",
+	"id":   "ID",
+	"ids":  "IDs",
+	"json": "JSON",
+	"uri":  "URI",
+	"url":  "URL",
+	"uuid": "UUID",
+	"xml":  "XML",
+}
+
+// Identifier converts s into exported CamelCase identifier
+func Identifier(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	xs := []string{}
+
+	for _, w := range words {
+		for _, x := range splitCamel(w) {
+			if v, ok := initialisms[strings.ToLower(x)]; ok {
+				xs = append(xs, v)
+				continue
+			}
+
+			rs := []rune(x)
+			xs = append(xs, string(unicode.ToUpper(rs[0]))+string(rs[1:]))
+		}
+	}
+
+	s = strings.Join(xs, "")
+
+	if s == "" {
+		return "X"
+	}
+
+	if unicode.IsDigit([]rune(s)[0]) {
+		s = "X" + s
+	}
+
+	return s
+}
+
+// splitCamel splits camelCase word into its parts
+func splitCamel(s string) []string {
+	xs := []string{}
+	rs := []rune(s)
+	start := 0
+
+	for i := 1; i < len(rs); i++ {
+		if unicode.IsUpper(rs[i]) && unicode.IsLower(rs[i-1]) {
+			xs = append(xs, string(rs[start:i]))
+			start = i
+		}
+	}
+
+	return append(xs, string(rs[start:]))
+}
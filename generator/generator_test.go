@@ -0,0 +1,420 @@
+package generator_test
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/subosito/snowboard/api"
+	"github.com/subosito/snowboard/generator"
+)
+
+func fixture() *api.API {
+	return &api.API{
+		Title:    "Notes API",
+		Metadata: []api.Metadata{{Key: "HOST", Value: "https://api.example.com/"}},
+		ResourceGroups: []api.ResourceGroup{
+			{
+				Title: "Notes",
+				Resources: []*api.Resource{
+					{
+						Title: "Notes",
+						Transitions: []*api.Transition{
+							{
+								Title:  "List Notes",
+								Method: "GET",
+								URL:    "https://api.example.com/notes{?limit,tag}",
+								Href: api.Href{
+									Parameters: []api.Parameter{
+										{Key: "limit", Kind: "number", Description: "Maximum number of notes"},
+										{Key: "tag", Kind: "string", Required: true},
+									},
+								},
+								Transactions: []api.Transaction{
+									{
+										Request: api.Request{Method: "GET"},
+										Response: api.Response{
+											StatusCode: 200,
+											Body:       api.Asset{ContentType: "application/json", Body: `[{"id": 1, "title": "Hello", "tags": ["a"]}]`},
+										},
+									},
+								},
+							},
+							{
+								Title:  "Create a Note",
+								Method: "POST",
+								URL:    "https://api.example.com/notes",
+								Transactions: []api.Transaction{
+									{
+										Request: api.Request{
+											Method: "POST",
+											Body:   api.Asset{ContentType: "application/json", Body: `{"title": "Hello"}`},
+											Schema: api.Asset{ContentType: "application/schema+json", Body: `{"type": "object", "required": ["title"], "properties": {"title": {"type": "string", "description": "Title of note"}, "author": {"type": "object", "properties": {"name": {"type": "string"}}}}}`},
+										},
+										Response: api.Response{
+											StatusCode: 201,
+											Body:       api.Asset{ContentType: "application/json", Body: `{"id": 2, "title": "Hello"}`},
+										},
+									},
+									{
+										Request: api.Request{Method: "POST"},
+										Response: api.Response{
+											StatusCode: 422,
+											Body:       api.Asset{ContentType: "application/json", Body: `{"error": "invalid"}`},
+										},
+									},
+								},
+							},
+						},
+					},
+					{
+						Title: "Note",
+						Transitions: []*api.Transition{
+							{
+								Method:    "DELETE",
+								Permalink: "notes-note-delete",
+								URL:       "https://api.example.com/notes/{id}",
+								Href: api.Href{
+									Parameters: []api.Parameter{{Key: "id", Kind: "number", Required: true}},
+								},
+								Transactions: []api.Transaction{
+									{
+										Request:  api.Request{Method: "DELETE"},
+										Response: api.Response{StatusCode: 204},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestOperations(t *testing.T) {
+	ops := generator.Operations(fixture())
+	assert.Len(t, ops, 3)
+
+	assert.Equal(t, "ListNotes", ops[0].Name)
+	assert.Equal(t, "/notes{?limit,tag}", ops[0].Path)
+	assert.Len(t, ops[0].PathParams, 0)
+	assert.Equal(t, []generator.Param{
+		{Key: "limit", Name: "Limit", Kind: "number", Description: "Maximum number of notes"},
+		{Key: "tag", Name: "Tag", Kind: "string", Required: true},
+	}, ops[0].QueryParams)
+	assert.Equal(t, "array", ops[0].Success().Type.Kind)
+	assert.Equal(t, "integer", ops[0].Success().Type.Elem.Fields[0].Type.Kind)
+
+	assert.Equal(t, "CreateANote", ops[1].Name)
+	assert.Equal(t, "title", ops[1].Request.Fields[0].Key)
+	assert.True(t, ops[1].Request.Fields[0].Required)
+	assert.Equal(t, "CreateANoteRequestAuthor", ops[1].Request.Fields[1].Type.Name)
+	assert.Len(t, ops[1].Failures(), 1)
+
+	assert.Equal(t, "NotesNoteDelete", ops[2].Name)
+	assert.Equal(t, "ID", ops[2].PathParams[0].Name)
+	assert.Nil(t, ops[2].Success().Type)
+}
+
+func TestIdentifier(t *testing.T) {
+	assert.Equal(t, "RetrieveANote", generator.Identifier("Retrieve a Note"))
+	assert.Equal(t, "UserID", generator.Identifier("user_id"))
+	assert.Equal(t, "CreatedAt", generator.Identifier("createdAt"))
+	assert.Equal(t, "X2fa", generator.Identifier("2fa"))
+}
+
+func TestGoClient(t *testing.T) {
+	b, err := generator.GoClient(fixture(), "notes")
+	assert.Nil(t, err)
+
+	s := string(b)
+	assert.Contains(t, s, "// Code generated by snowboard. DO NOT EDIT.")
+	assert.Contains(t, s, "package notes")
+	assert.Contains(t, s, `const DefaultBaseURL = "https://api.example.com"`)
+	assert.Contains(t, s, "func (c *Client) ListNotes(ctx context.Context, params *ListNotesParams) (*ListNotesResponse200, error) {")
+	assert.Contains(t, s, "func (c *Client) CreateANote(ctx context.Context, body CreateANoteRequest) (*CreateANoteResponse201, error) {")
+	assert.Contains(t, s, "func (c *Client) NotesNoteDelete(ctx context.Context, id float64) error {")
+	assert.Contains(t, s, `u := c.BaseURL + "/notes/" + url.PathEscape(formatParam(id))`)
+	assert.Contains(t, s, "type CreateANoteError422 struct {")
+	assert.Contains(t, s, "Author *CreateANoteRequestAuthor `json:\"author,omitempty\"`")
+	assert.Contains(t, s, "type ListNotesResponse200 []ListNotesResponse200Item")
+}
+
+func TestGoServer(t *testing.T) {
+	b, err := generator.GoServer(fixture(), "notes")
+	assert.Nil(t, err)
+
+	s := string(b)
+	assert.Contains(t, s, "// Code generated by snowboard. DO NOT EDIT.")
+	assert.Contains(t, s, "\tListNotes(w *ListNotesWriter, r *ListNotesParams)\n")
+	assert.Contains(t, s, `{method: "DELETE", pattern: "/notes/:id", handle:`)
+	assert.Contains(t, s, `return nil, fmt.Errorf("missing required query parameter tag")`)
+	assert.Contains(t, s, `if err := requireFields(data, "title"); err != nil {`)
+	assert.Contains(t, s, "func (w *CreateANoteWriter) Write201(body CreateANoteResponse201) error {")
+	assert.Contains(t, s, "func (w *NotesNoteDeleteWriter) Write204() {")
+
+	b, err = generator.GoServerStub(fixture(), "notes")
+	assert.Nil(t, err)
+	assert.NotContains(t, string(b), "DO NOT EDIT")
+	assert.Contains(t, string(b), "func (s *Service) CreateANote(w *CreateANoteWriter, r *CreateANoteParams) {")
+}
+
+func TestTypeScript(t *testing.T) {
+	b, err := generator.TypeScript(fixture())
+	assert.Nil(t, err)
+
+	s := string(b)
+	assert.Contains(t, s, `export const DEFAULT_BASE_URL = "https://api.example.com";`)
+	assert.Contains(t, s, "export interface CreateANoteRequest {\n  /** Title of note */\n  title: string;\n  author?: CreateANoteRequestAuthor;\n}")
+	assert.Contains(t, s, "export type ListNotesResponse200 = ListNotesResponse200Item[];")
+	assert.Contains(t, s, "export interface ListNotesParams {\n  /** Maximum number of notes */\n  limit?: number;\n  tag: string;\n}")
+	assert.Contains(t, s, "  | { status: 201; body: CreateANoteResponse201 }\n  | { status: 422; body: CreateANoteResponse422 };")
+	assert.Contains(t, s, "async createANote(body: CreateANoteRequest, init?: RequestInit): Promise<CreateANoteResponse> {")
+	assert.Contains(t, s, `this.request("DELETE", "/notes/" + encodeURIComponent(formatParam(params.id)), {}, "", undefined, init);`)
+}
+
+func TestGoClient_typeNames(t *testing.T) {
+	b := fixture()
+	r := b.ResourceGroups[0].Resources[1]
+
+	// "List Notes Response200 Item" definition is named like element type of
+	// List Notes response, yet differs in shape
+	r.Transitions = append(r.Transitions, &api.Transition{
+		Title:  "Retrieve a Note",
+		Method: "GET",
+		URL:    "https://api.example.com/notes/{id}",
+		Transactions: []api.Transaction{
+			{
+				Request: api.Request{Method: "GET"},
+				Response: api.Response{
+					StatusCode: 200,
+					Schema:     api.Asset{Body: `{"type": "object", "properties": {"item": {"$ref": "#/definitions/List Notes Response200 Item"}}, "definitions": {"List Notes Response200 Item": {"type": "object", "properties": {"summary": {"type": "string"}}}}}`},
+				},
+			},
+		},
+	})
+
+	src, err := generator.GoClient(b, "notes")
+	assert.Nil(t, err)
+
+	s := string(src)
+	assert.Contains(t, s, "type ListNotesResponse200Item struct {\n\tID ")
+	assert.Contains(t, s, "type ListNotesResponse200Item2 struct {\n\tSummary string")
+	assert.Contains(t, s, "Item *ListNotesResponse200Item2 `json:\"item,omitempty\"`")
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "client.go", src, 0)
+	assert.Nil(t, err)
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("notes", fset, []*ast.File{f}, nil)
+	assert.Nil(t, err)
+}
+
+func TestGoServer_routeOrder(t *testing.T) {
+	b := fixture()
+	r := b.ResourceGroups[0].Resources[1]
+
+	r.Transitions = append(r.Transitions, &api.Transition{
+		Title:        "Search Notes",
+		Method:       "GET",
+		URL:          "https://api.example.com/notes/search{?q}",
+		Transactions: []api.Transaction{{Request: api.Request{Method: "GET"}, Response: api.Response{StatusCode: 204}}},
+	})
+
+	src, err := generator.GoServer(b, "notes")
+	assert.Nil(t, err)
+
+	s := string(src)
+	search := strings.Index(s, `{method: "GET", pattern: "/notes/search"`)
+	note := strings.Index(s, `{method: "DELETE", pattern: "/notes/:id"`)
+	assert.True(t, search != -1 && note != -1 && search < note)
+	assert.NotContains(t, s, "PathUnescape")
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "server.go", src, 0)
+	assert.Nil(t, err)
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("notes", fset, []*ast.File{f}, nil)
+	assert.Nil(t, err)
+}
+
+func TestNewType_recursive(t *testing.T) {
+	schema := `{"$ref": "#/definitions/Node", "definitions": {"Node": {"type": "object", "required": ["value", "parent"], "properties": {
+		"value": {"type": "string"},
+		"parent": {"$ref": "#/definitions/Node"},
+		"left": {"$ref": "#/definitions/Node"},
+		"right": {"$ref": "#/definitions/Node"},
+		"children": {"type": "array", "items": {"$ref": "#/definitions/Node"}}
+	}}}}`
+
+	x := generator.NewType("Tree", schema, "")
+	assert.Equal(t, "Node", x.Name)
+	assert.Len(t, x.Fields, 5)
+
+	for _, f := range x.Fields[1:4] {
+		assert.True(t, f.Type == x)
+	}
+
+	assert.True(t, x.Fields[4].Type.Elem == x)
+
+	b := fixture()
+	r := b.ResourceGroups[0].Resources[1]
+	r.Transitions = append(r.Transitions, &api.Transition{
+		Title:        "Retrieve a Tree",
+		Method:       "GET",
+		URL:          "https://api.example.com/tree",
+		Transactions: []api.Transaction{{Request: api.Request{Method: "GET"}, Response: api.Response{StatusCode: 200, Schema: api.Asset{Body: schema}}}},
+	})
+
+	src, err := generator.GoClient(b, "notes")
+	assert.Nil(t, err)
+
+	s := string(src)
+	assert.Contains(t, s, "\tParent   *Node  `json:\"parent\"`\n")
+	assert.Contains(t, s, "\tLeft     *Node  `json:\"left,omitempty\"`\n")
+	assert.Contains(t, s, "\tChildren []Node `json:\"children,omitempty\"`\n")
+	assert.NotContains(t, s, "Node2")
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "client.go", src, 0)
+	assert.Nil(t, err)
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("notes", fset, []*ast.File{f}, nil)
+	assert.Nil(t, err)
+
+	src, err = generator.TypeScript(b)
+	assert.Nil(t, err)
+	assert.Contains(t, string(src), "  children?: Node[];\n")
+}
+
+func TestGoClient_reservedNames(t *testing.T) {
+	b := fixture()
+	r := b.ResourceGroups[0].Resources[1]
+
+	schema := `{"type": "object", "properties": {
+		"error": {"$ref": "#/definitions/Error"},
+		"client": {"$ref": "#/definitions/Client"}
+	}, "definitions": {
+		"Error": {"type": "object", "properties": {"message": {"type": "string"}}},
+		"Client": {"type": "object", "properties": {"name": {"type": "string"}}}
+	}}`
+
+	r.Transitions = append(r.Transitions, &api.Transition{
+		Title:  "Header",
+		Method: "GET",
+		URL:    "https://api.example.com/links/{url}/{c}/{ctx}{?q}",
+		Transactions: []api.Transaction{
+			{Request: api.Request{Method: "GET"}, Response: api.Response{StatusCode: 200, Schema: api.Asset{Body: schema}}},
+			{Request: api.Request{Method: "GET"}, Response: api.Response{StatusCode: 404, Schema: api.Asset{Body: `{"$ref": "#/definitions/Error", "definitions": {"Error": {"type": "object", "properties": {"message": {"type": "string"}}}}}`}}},
+		},
+	})
+
+	src, err := generator.GoClient(b, "notes")
+	assert.Nil(t, err)
+
+	s := string(src)
+	assert.Contains(t, s, "func (c *Client) Header2(ctx context.Context, url_ string, c_ string, ctx_ string, params *Header2Params) (*Header2Response200, error) {")
+	assert.Contains(t, s, "type Error2 struct {")
+	assert.Contains(t, s, "type Client2 struct {")
+	assert.Contains(t, s, "Response Error2\n")
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "client.go", src, 0)
+	assert.Nil(t, err)
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("notes", fset, []*ast.File{f}, nil)
+	assert.Nil(t, err)
+}
+
+func TestGoServer_reservedNames(t *testing.T) {
+	b := fixture()
+	r := b.ResourceGroups[0].Resources[1]
+
+	schema := `{"type": "object", "properties": {
+		"handler": {"$ref": "#/definitions/Handler"},
+		"service": {"$ref": "#/definitions/Service"}
+	}, "definitions": {
+		"Handler": {"type": "object", "properties": {"name": {"type": "string"}}},
+		"Service": {"type": "object", "properties": {"name": {"type": "string"}}}
+	}}`
+
+	r.Transitions = append(r.Transitions, &api.Transition{
+		Title:  "Tag Note",
+		Method: "POST",
+		URL:    "https://api.example.com/notes/{request}/tags{?body}",
+		Transactions: []api.Transaction{
+			{
+				Request:  api.Request{Method: "POST", Schema: api.Asset{Body: `{"type": "object", "properties": {"tag": {"type": "string"}}}`}},
+				Response: api.Response{StatusCode: 200, Schema: api.Asset{Body: schema}},
+			},
+		},
+	})
+
+	src, err := generator.GoServer(b, "notes")
+	assert.Nil(t, err)
+
+	s := string(src)
+	assert.Contains(t, s, "\tRequest2 string\n\tBody2    *string\n")
+	assert.Contains(t, s, "type Handler2 struct {")
+	assert.Contains(t, s, "type Service2 struct {")
+
+	stub, err := generator.GoServerStub(b, "notes")
+	assert.Nil(t, err)
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "server.go", src, 0)
+	assert.Nil(t, err)
+
+	g, err := parser.ParseFile(fset, "service.go", stub, 0)
+	assert.Nil(t, err)
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("notes", fset, []*ast.File{f, g}, nil)
+	assert.Nil(t, err)
+}
+
+func TestTypeScript_reservedNames(t *testing.T) {
+	b := fixture()
+	r := b.ResourceGroups[0].Resources[1]
+
+	schema := `{"type": "object", "properties": {
+		"error": {"$ref": "#/definitions/Error"},
+		"apiError": {"$ref": "#/definitions/ApiError"},
+		"client": {"$ref": "#/definitions/Client"}
+	}, "definitions": {
+		"Error": {"type": "object", "properties": {"message": {"type": "string"}}},
+		"ApiError": {"type": "object", "properties": {"code": {"type": "number"}}},
+		"Client": {"type": "object", "properties": {"name": {"type": "string"}}}
+	}}`
+
+	r.Transitions = append(r.Transitions, &api.Transition{
+		Title:  "Request",
+		Method: "GET",
+		URL:    "https://api.example.com/requests",
+		Transactions: []api.Transaction{
+			{Request: api.Request{Method: "GET"}, Response: api.Response{StatusCode: 200, Schema: api.Asset{Body: schema}}},
+		},
+	})
+
+	src, err := generator.TypeScript(b)
+	assert.Nil(t, err)
+
+	s := string(src)
+	assert.Contains(t, s, "export class ApiError extends Error {")
+	assert.Contains(t, s, "export interface Error2 {")
+	assert.Contains(t, s, "export interface APIError {")
+	assert.Contains(t, s, "export interface Client2 {")
+	assert.Contains(t, s, "async request2(init?: RequestInit): Promise<Request2Response> {")
+	assert.NotContains(t, s, "export interface Error {")
+	assert.NotContains(t, s, "export interface Client {")
+}
@@ -0,0 +1,274 @@
+package generator
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/subosito/snowboard/api"
+)
+
+// GoClient generates Go client package with one method for each transition
+func GoClient(b *api.API, pkg string) ([]byte, error) {
+	w := newGoWriter()
+	ops := Operations(b)
+
+	w.p("// DefaultBaseURL is the documented host of API")
+	w.p("const DefaultBaseURL = %q", strings.TrimSuffix(b.Host(), "/"))
+	w.p("")
+	w.p("// Client performs requests to API")
+	w.p("type Client struct {")
+	w.p("\tBaseURL    string")
+	w.p("\tHTTPClient *http.Client")
+	w.p("\tHeader     http.Header")
+	w.p("}")
+	w.p("")
+	w.p("// New returns client of API hosted on baseURL")
+	w.p("func New(baseURL string) *Client {")
+	w.p("\treturn &Client{BaseURL: strings.TrimSuffix(baseURL, \"/\"), HTTPClient: http.DefaultClient, Header: http.Header{}}")
+	w.p("}")
+	w.p("")
+	w.p("// Error is returned when API responds with undocumented status code")
+	w.p("type Error struct {")
+	w.p("\tStatusCode int")
+	w.p("\tBody       []byte")
+	w.p("}")
+	w.p("")
+	w.p("func (e *Error) Error() string {")
+	w.p("\treturn fmt.Sprintf(\"unexpected status code %%d: %%s\", e.StatusCode, e.Body)")
+	w.p("}")
+	w.p("")
+	w.raw(goClientDo)
+	w.raw(goFormatParam)
+
+	for _, op := range ops {
+		writeClientOperation(w, op)
+	}
+
+	for _, op := range ops {
+		w.declareType(op.Request)
+
+		for _, r := range op.Responses {
+			w.declareType(r.Type)
+		}
+	}
+
+	doc := ""
+	if b.Title != "" {
+		doc = "is a client of " + b.Title
+	}
+
+	return w.source(pkg, doc)
+}
+
+func writeClientOperation(w *goWriter, op *Operation) {
+	vars := map[string]string{}
+	args := []string{"ctx context.Context"}
+
+	used := map[string]bool{}
+
+	for _, p := range op.PathParams {
+		n := goArgument(p.Name, used)
+		vars[p.Key] = n
+		args = append(args, n+" "+goParamType(p))
+	}
+
+	if len(op.QueryParams) > 0 {
+		args = append(args, "params *"+op.Name+"Params")
+	}
+
+	if op.Request != nil {
+		args = append(args, "body "+op.Request.Name)
+	}
+
+	success := op.Success()
+	result := "error"
+	if success != nil && success.Type != nil {
+		result = "(*" + success.Type.Name + ", error)"
+	}
+
+	fail := "err"
+	if result != "error" {
+		fail = "nil, err"
+	}
+
+	if len(op.QueryParams) > 0 {
+		w.p("// %sParams are query parameters of %s", op.Name, op.Name)
+		w.p("type %sParams struct {", op.Name)
+		for _, p := range op.QueryParams {
+			if p.Description != "" {
+				w.comment("\t", p.Description)
+			}
+
+			if p.Required {
+				w.p("\t%s %s", p.Name, goParamType(p))
+			} else {
+				w.p("\t%s *%s", p.Name, goParamType(p))
+			}
+		}
+		w.p("}")
+		w.p("")
+	}
+
+	for _, r := range op.Failures() {
+		n := op.Name + "Error" + strconv.Itoa(r.StatusCode)
+
+		w.p("// %s is returned when API responds with documented %d status code", n, r.StatusCode)
+		w.p("type %s struct {", n)
+		if r.Type != nil {
+			w.p("\tResponse %s", r.Type.Name)
+		}
+		w.p("\tBody []byte")
+		w.p("}")
+		w.p("")
+		w.p("func (e *%s) Error() string {", n)
+		w.p("\treturn fmt.Sprintf(\"%s: status code %d: %%s\", e.Body)", op.Name, r.StatusCode)
+		w.p("}")
+		w.p("")
+	}
+
+	title := op.Title
+	if title == "" {
+		title = op.Method + " " + op.Path
+	}
+
+	w.p("// %s performs %s", op.Name, title)
+	if op.Description != "" {
+		w.p("//")
+		w.comment("", op.Description)
+	}
+	w.p("func (c *Client) %s(%s) %s {", op.Name, strings.Join(args, ", "), result)
+	w.p("\tu := c.BaseURL + %s", goPathExpr(op.Path, vars))
+
+	if len(op.QueryParams) > 0 {
+		w.p("\tq := url.Values{}")
+		w.p("\tif params != nil {")
+		for _, p := range op.QueryParams {
+			if p.Required {
+				w.p("\t\tq.Set(%q, formatParam(params.%s))", p.Key, p.Name)
+			} else {
+				w.p("\t\tif params.%s != nil {", p.Name)
+				w.p("\t\t\tq.Set(%q, formatParam(*params.%s))", p.Key, p.Name)
+				w.p("\t\t}")
+			}
+		}
+		w.p("\t}")
+		w.p("\tif len(q) > 0 {")
+		w.p("\t\tu += \"?\" + q.Encode()")
+		w.p("\t}")
+	}
+
+	w.p("\tvar r io.Reader")
+	contentType := ""
+
+	if op.Request != nil {
+		contentType = op.ContentType
+
+		if op.Request.Kind == "text" {
+			w.p("\tr = strings.NewReader(string(body))")
+		} else {
+			if contentType == "" {
+				contentType = "application/json"
+			}
+
+			w.p("\tb, err := json.Marshal(body)")
+			w.p("\tif err != nil {")
+			w.p("\t\treturn %s", fail)
+			w.p("\t}")
+			w.p("\tr = bytes.NewReader(b)")
+		}
+	}
+
+	w.p("\tcode, data, err := c.do(ctx, %q, u, %q, r)", op.Method, contentType)
+	w.p("\tif err != nil {")
+	w.p("\t\treturn %s", fail)
+	w.p("\t}")
+	w.p("")
+	w.p("\tswitch {")
+
+	if success != nil {
+		w.p("\tcase code >= 200 && code < 300:")
+
+		if success.Type == nil {
+			w.p("\t\treturn nil")
+		} else if success.Type.Kind == "text" {
+			w.p("\t\tout := %s(data)", success.Type.Name)
+			w.p("\t\treturn &out, nil")
+		} else {
+			w.p("\t\tvar out %s", success.Type.Name)
+			w.p("\t\tif len(data) > 0 {")
+			w.p("\t\t\tif err := json.Unmarshal(data, &out); err != nil {")
+			w.p("\t\t\t\treturn nil, err")
+			w.p("\t\t\t}")
+			w.p("\t\t}")
+			w.p("\t\treturn &out, nil")
+		}
+	}
+
+	for _, r := range op.Failures() {
+		n := op.Name + "Error" + strconv.Itoa(r.StatusCode)
+
+		w.p("\tcase code == %d:", r.StatusCode)
+		w.p("\t\te := &%s{Body: data}", n)
+
+		if r.Type != nil {
+			if r.Type.Kind == "text" {
+				w.p("\t\te.Response = %s(data)", r.Type.Name)
+			} else {
+				w.p("\t\tjson.Unmarshal(data, &e.Response)")
+			}
+		}
+
+		if result == "error" {
+			w.p("\t\treturn e")
+		} else {
+			w.p("\t\treturn nil, e")
+		}
+	}
+
+	w.p("\t}")
+	w.p("")
+
+	if result == "error" {
+		w.p("\treturn &Error{StatusCode: code, Body: data}")
+	} else {
+		w.p("\treturn nil, &Error{StatusCode: code, Body: data}")
+	}
+
+	w.p("}")
+	w.p("")
+}
+
+const goClientDo = `func (c *Client) do(ctx context.Context, method, u, contentType string, body io.Reader) (int, []byte, error) {
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req = req.WithContext(ctx)
+
+	for k, vs := range c.Header {
+		req.Header[k] = vs
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return resp.StatusCode, data, nil
+}`
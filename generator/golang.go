@@ -0,0 +1,310 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// goWriter accumulates Go source code
+type goWriter struct {
+	bytes.Buffer
+	declared map[string]bool
+}
+
+func newGoWriter() *goWriter {
+	return &goWriter{declared: map[string]bool{}}
+}
+
+func (w *goWriter) p(format string, args ...interface{}) {
+	fmt.Fprintf(w, format, args...)
+	w.WriteByte('\n')
+}
+
+func (w *goWriter) raw(s string) {
+	w.WriteString(s)
+	w.WriteByte('\n')
+}
+
+func (w *goWriter) comment(prefix, s string) {
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		w.p("%s// %s", prefix, strings.TrimSpace(line))
+	}
+}
+
+var goCommentPattern = regexp.MustCompile(`(?m)^\s*//.*$`)
+
+// goImports lists packages which generated code may refer to
+var goImports = []string{
+	"bytes",
+	"context",
+	"encoding/json",
+	"fmt",
+	"io",
+	"io/ioutil",
+	"net/http",
+	"net/url",
+	"strconv",
+	"strings",
+}
+
+// goPackage reports whether s is name of package in goImports
+func goPackage(s string) bool {
+	for _, x := range goImports {
+		if x[strings.LastIndex(x, "/")+1:] == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// source returns gofmt-ed source file of package pkg. Only packages which are
+// referred by the written code are imported.
+func (w *goWriter) source(pkg, doc string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintln(&buf, "// Code generated by snowboard. DO NOT EDIT.")
+	fmt.Fprintln(&buf)
+
+	if doc != "" {
+		fmt.Fprintf(&buf, "// Package %s %s\n", pkg, doc)
+	}
+
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	fmt.Fprintln(&buf, "import (")
+
+	code := goCommentPattern.ReplaceAll(w.Bytes(), nil)
+
+	for _, s := range goImports {
+		name := s[strings.LastIndex(s, "/")+1:]
+		re := regexp.MustCompile(`(^|[^\w.])` + name + `\.`)
+
+		if re.Match(code) {
+			fmt.Fprintf(&buf, "\t%q\n", s)
+		}
+	}
+
+	fmt.Fprintln(&buf, ")")
+	fmt.Fprintln(&buf)
+	buf.Write(w.Bytes())
+
+	b, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("Unable to format generated code: %s", err)
+	}
+
+	return b, nil
+}
+
+// declareType writes type declaration of t and its nested object types
+func (w *goWriter) declareType(t *Type) {
+	if t == nil || w.declared[t.Name] {
+		return
+	}
+
+	w.declared[t.Name] = true
+	nested := []*Type{}
+
+	if t.Description != "" {
+		w.comment("", t.Name+" "+t.Description)
+	}
+
+	switch t.Kind {
+	case "object":
+		w.p("type %s struct {", t.Name)
+
+		for _, f := range t.Fields {
+			if f.Description != "" {
+				w.comment("\t", f.Description)
+			}
+
+			tag := f.Key
+			typ := goType(f.Type)
+
+			if !f.Required {
+				tag += ",omitempty"
+			}
+
+			if f.Type.Kind == "object" && (!f.Required || embeds(f.Type, t, map[*Type]bool{})) {
+				typ = "*" + typ
+			}
+
+			w.p("\t%s %s `json:%s`", f.Name, typ, strconv.Quote(tag))
+			nested = append(nested, objectTypes(f.Type)...)
+		}
+
+		w.p("}")
+	case "array":
+		w.p("type %s []%s", t.Name, goType(t.Elem))
+		nested = append(nested, objectTypes(t.Elem)...)
+	default:
+		w.p("type %s %s", t.Name, goType(t))
+	}
+
+	w.p("")
+
+	for _, n := range nested {
+		w.declareType(n)
+	}
+}
+
+// embeds reports whether value of object type t contains value of type x
+// through its required object fields, which makes declaring such fields by
+// value recursive
+func embeds(t, x *Type, seen map[*Type]bool) bool {
+	if t == x {
+		return true
+	}
+
+	if seen[t] {
+		return false
+	}
+
+	seen[t] = true
+
+	for _, f := range t.Fields {
+		if f.Required && f.Type.Kind == "object" && embeds(f.Type, x, seen) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// objectTypes returns named object types referenced by t
+func objectTypes(t *Type) []*Type {
+	switch {
+	case t == nil:
+		return nil
+	case t.Kind == "object":
+		return []*Type{t}
+	case t.Kind == "array":
+		return objectTypes(t.Elem)
+	}
+
+	return nil
+}
+
+// goType returns Go type expression of t, objects are referred by name
+func goType(t *Type) string {
+	if t == nil {
+		return "interface{}"
+	}
+
+	switch t.Kind {
+	case "object":
+		return t.Name
+	case "array":
+		return "[]" + goType(t.Elem)
+	case "string", "text":
+		return "string"
+	case "number":
+		return "float64"
+	case "integer":
+		return "int64"
+	case "boolean":
+		return "bool"
+	}
+
+	return "interface{}"
+}
+
+// goParamType returns Go type of path or query parameter
+func goParamType(p Param) string {
+	switch p.Kind {
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]string"
+	}
+
+	return "string"
+}
+
+var goKeywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true,
+	"default": true, "defer": true, "else": true, "fallthrough": true, "for": true,
+	"func": true, "go": true, "goto": true, "if": true, "import": true,
+	"interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+}
+
+// goLocals are names generated methods of client declare or import, which
+// their arguments must not shadow
+var goLocals = map[string]bool{
+	"c": true, "ctx": true, "u": true, "q": true, "r": true, "b": true, "e": true,
+	"body": true, "data": true, "code": true, "err": true, "out": true, "params": true,
+	"formatParam": true,
+}
+
+// goArgument returns unexported name of argument s, suffixed when it's taken
+// by another argument or names of goLocals
+func goArgument(s string, used map[string]bool) string {
+	s = lowerIdentifier(s)
+
+	for used[s] || goLocals[s] || goPackage(s) {
+		s += "_"
+	}
+
+	used[s] = true
+	return s
+}
+
+// lowerIdentifier converts exported identifier into unexported one, e.g.
+// UserID into userID and URLPath into urlPath
+func lowerIdentifier(s string) string {
+	rs := []rune(s)
+
+	for i := 0; i < len(rs); i++ {
+		if !unicode.IsUpper(rs[i]) {
+			break
+		}
+
+		if i > 0 && i+1 < len(rs) && unicode.IsLower(rs[i+1]) {
+			break
+		}
+
+		rs[i] = unicode.ToLower(rs[i])
+	}
+
+	s = string(rs)
+
+	if goKeywords[s] {
+		s += "_"
+	}
+
+	return s
+}
+
+// goPathExpr converts path template into Go string expression where each
+// expression is substituted by escaped variable. Query expressions are omitted.
+func goPathExpr(path string, vars map[string]string) string {
+	return pathExpr(path, func(key string, escape bool) string {
+		v := fmt.Sprintf("formatParam(%s)", vars[key])
+		if escape {
+			v = fmt.Sprintf("url.PathEscape(%s)", v)
+		}
+
+		return v
+	})
+}
+
+const goFormatParam = `
+func formatParam(v interface{}) string {
+	switch x := v.(type) {
+	case []string:
+		return strings.Join(x, ",")
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	}
+
+	return fmt.Sprint(v)
+}
+`
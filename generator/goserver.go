@@ -0,0 +1,366 @@
+package generator
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/subosito/snowboard/api"
+	"github.com/subosito/snowboard/parser"
+)
+
+// GoServer generates Go server package: handler interface with one method for
+// each transition, router, request decoding and typed response writers
+func GoServer(b *api.API, pkg string) ([]byte, error) {
+	w := newGoWriter()
+	ops := Operations(b)
+
+	w.p("// Handler implements actions of API")
+	w.p("type Handler interface {")
+	for _, op := range ops {
+		w.p("\t%s(w *%sWriter, r *%sParams)", op.Name, op.Name, op.Name)
+	}
+	w.p("}")
+	w.p("")
+	w.p("// NewRouter returns HTTP handler which routes requests to h")
+	w.p("func NewRouter(h Handler) http.Handler {")
+	w.p("\treturn &router{routes: []route{")
+	for _, op := range routeOrder(ops) {
+		w.p("\t\t{method: %q, pattern: %q, handle: func(w http.ResponseWriter, r *http.Request, vars map[string]string) {", op.Method, parser.RoutePattern(op.Path, ""))
+		w.p("\t\t\tp, err := decode%s(r, vars)", op.Name)
+		w.p("\t\t\tif err != nil {")
+		w.p("\t\t\t\thttp.Error(w, err.Error(), http.StatusBadRequest)")
+		w.p("\t\t\t\treturn")
+		w.p("\t\t\t}")
+		w.p("\t\t\th.%s(&%sWriter{ResponseWriter: w}, p)", op.Name, op.Name)
+		w.p("\t\t}},")
+	}
+	w.p("\t}}")
+	w.p("}")
+	w.p("")
+	w.raw(goServerRouter)
+	w.raw(goServerHelpers)
+
+	for _, op := range ops {
+		writeServerOperation(w, op)
+	}
+
+	for _, op := range ops {
+		w.declareType(op.Request)
+
+		for _, r := range op.Responses {
+			w.declareType(r.Type)
+		}
+	}
+
+	doc := ""
+	if b.Title != "" {
+		doc = "is a server of " + b.Title
+	}
+
+	return w.source(pkg, doc)
+}
+
+// routeOrder orders operations as routes are tried, static path segments
+// before parameters, so routes take precedence as in mock server
+func routeOrder(ops []*Operation) []*Operation {
+	xs := append([]*Operation{}, ops...)
+	kinds := map[*Operation][]bool{}
+
+	for _, op := range xs {
+		for _, seg := range strings.Split(strings.Trim(parser.RoutePattern(op.Path, ""), "/"), "/") {
+			kinds[op] = append(kinds[op], strings.HasPrefix(seg, ":"))
+		}
+	}
+
+	sort.SliceStable(xs, func(i, j int) bool {
+		a, b := kinds[xs[i]], kinds[xs[j]]
+
+		for n := 0; n < len(a) && n < len(b); n++ {
+			if a[n] != b[n] {
+				return !a[n]
+			}
+		}
+
+		return len(a) < len(b)
+	})
+
+	return xs
+}
+
+// GoServerStub generates a starting implementation of Handler interface
+// generated by GoServer. It's meant to be generated once and edited by hand.
+func GoServerStub(b *api.API, pkg string) ([]byte, error) {
+	w := newGoWriter()
+	ops := Operations(b)
+
+	w.p("// Service implements Handler")
+	w.p("type Service struct{}")
+	w.p("")
+
+	for _, op := range ops {
+		w.p("// %s handles %s %s", op.Name, op.Method, op.Path)
+		w.p("func (s *Service) %s(w *%sWriter, r *%sParams) {", op.Name, op.Name, op.Name)
+		w.p("\thttp.Error(w, http.StatusText(http.StatusNotImplemented), http.StatusNotImplemented)")
+		w.p("}")
+		w.p("")
+	}
+
+	src, err := w.source(pkg, "")
+	if err != nil {
+		return nil, err
+	}
+
+	s := strings.Replace(string(src), "// Code generated by snowboard. DO NOT EDIT.\n\n", "", 1)
+	return []byte(s), nil
+}
+
+// serverFields returns names of fields of decoded request holding
+// parameters, suffixed when named like Request or Body fields
+func serverFields(op *Operation) map[string]string {
+	used := map[string]bool{"Request": true, "Body": true}
+	names := map[string]string{}
+
+	for _, p := range append(append([]Param{}, op.PathParams...), op.QueryParams...) {
+		name := p.Name
+
+		for n := 2; used[name]; n++ {
+			name = p.Name + strconv.Itoa(n)
+		}
+
+		used[name] = true
+		names[p.Key] = name
+	}
+
+	return names
+}
+
+func writeServerOperation(w *goWriter, op *Operation) {
+	fields := serverFields(op)
+
+	w.p("// %sParams is a decoded request of %s", op.Name, op.Name)
+	w.p("type %sParams struct {", op.Name)
+	w.p("\tRequest *http.Request")
+
+	for _, p := range append(op.PathParams, op.QueryParams...) {
+		if p.Description != "" {
+			w.comment("\t", p.Description)
+		}
+
+		if p.Required {
+			w.p("\t%s %s", fields[p.Key], goParamType(p))
+		} else {
+			w.p("\t%s *%s", fields[p.Key], goParamType(p))
+		}
+	}
+
+	if op.Request != nil {
+		w.p("\tBody %s", op.Request.Name)
+	}
+
+	w.p("}")
+	w.p("")
+
+	w.p("func decode%s(r *http.Request, vars map[string]string) (*%sParams, error) {", op.Name, op.Name)
+	w.p("\tp := &%sParams{Request: r}", op.Name)
+
+	if len(op.QueryParams) > 0 {
+		w.p("\tq := r.URL.Query()")
+	}
+
+	for _, p := range op.PathParams {
+		w.p("\tif err := parseParam(vars[%q], &p.%s); err != nil {", p.Key, fields[p.Key])
+		w.p("\t\treturn nil, fmt.Errorf(\"invalid path parameter %s: %%s\", err)", p.Key)
+		w.p("\t}")
+	}
+
+	for _, p := range op.QueryParams {
+		if p.Required {
+			w.p("\tif _, ok := q[%q]; !ok {", p.Key)
+			w.p("\t\treturn nil, fmt.Errorf(\"missing required query parameter %s\")", p.Key)
+			w.p("\t}")
+			w.p("\tif err := parseParam(q.Get(%q), &p.%s); err != nil {", p.Key, fields[p.Key])
+			w.p("\t\treturn nil, fmt.Errorf(\"invalid query parameter %s: %%s\", err)", p.Key)
+			w.p("\t}")
+		} else {
+			w.p("\tif _, ok := q[%q]; ok {", p.Key)
+			w.p("\t\tp.%s = new(%s)", fields[p.Key], goParamType(p))
+			w.p("\t\tif err := parseParam(q.Get(%q), p.%s); err != nil {", p.Key, fields[p.Key])
+			w.p("\t\t\treturn nil, fmt.Errorf(\"invalid query parameter %s: %%s\", err)", p.Key)
+			w.p("\t\t}")
+			w.p("\t}")
+		}
+	}
+
+	if op.Request != nil {
+		w.p("\tdata, err := ioutil.ReadAll(r.Body)")
+		w.p("\tif err != nil {")
+		w.p("\t\treturn nil, err")
+		w.p("\t}")
+
+		if op.Request.Kind == "text" {
+			w.p("\tp.Body = %s(data)", op.Request.Name)
+		} else {
+			required := []string{}
+			for _, f := range op.Request.Fields {
+				if f.Required {
+					required = append(required, strconv.Quote(f.Key))
+				}
+			}
+
+			if len(required) > 0 {
+				w.p("\tif err := requireFields(data, %s); err != nil {", strings.Join(required, ", "))
+				w.p("\t\treturn nil, err")
+				w.p("\t}")
+			}
+
+			w.p("\tif err := json.Unmarshal(data, &p.Body); err != nil {")
+			w.p("\t\treturn nil, fmt.Errorf(\"invalid body: %%s\", err)")
+			w.p("\t}")
+		}
+	}
+
+	w.p("\treturn p, nil")
+	w.p("}")
+	w.p("")
+
+	w.p("// %sWriter writes documented responses of %s", op.Name, op.Name)
+	w.p("type %sWriter struct {", op.Name)
+	w.p("\thttp.ResponseWriter")
+	w.p("}")
+	w.p("")
+
+	for _, r := range op.Responses {
+		code := strconv.Itoa(r.StatusCode)
+
+		if r.Type == nil {
+			w.p("// Write%s writes %s response without body", code, code)
+			w.p("func (w *%sWriter) Write%s() {", op.Name, code)
+			w.p("\tw.WriteHeader(%d)", r.StatusCode)
+			w.p("}")
+			w.p("")
+			continue
+		}
+
+		contentType := r.ContentType
+		if contentType == "" && r.Type.Kind != "text" {
+			contentType = "application/json"
+		}
+
+		w.p("// Write%s writes %s response", code, code)
+		w.p("func (w *%sWriter) Write%s(body %s) error {", op.Name, code, r.Type.Name)
+
+		if contentType != "" {
+			w.p("\tw.Header().Set(\"Content-Type\", %q)", contentType)
+		}
+
+		w.p("\tw.WriteHeader(%d)", r.StatusCode)
+
+		if r.Type.Kind == "text" {
+			w.p("\t_, err := io.WriteString(w, string(body))")
+			w.p("\treturn err")
+		} else {
+			w.p("\treturn json.NewEncoder(w).Encode(body)")
+		}
+
+		w.p("}")
+		w.p("")
+	}
+}
+
+const goServerRouter = `type route struct {
+	method  string
+	pattern string
+	handle  func(w http.ResponseWriter, r *http.Request, vars map[string]string)
+}
+
+type router struct {
+	routes []route
+}
+
+func (rt *router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	allowed := []string{}
+
+	for _, x := range rt.routes {
+		vars, ok := matchPattern(x.pattern, r.URL.Path)
+		if !ok {
+			continue
+		}
+
+		if x.method == r.Method {
+			x.handle(w, r, vars)
+			return
+		}
+
+		allowed = append(allowed, x.method)
+	}
+
+	if len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	http.NotFound(w, r)
+}
+
+// matchPattern matches path, decoded already, against pattern such as
+// "/notes/:id"
+func matchPattern(pattern, path string) (map[string]string, bool) {
+	ps := strings.Split(strings.Trim(pattern, "/"), "/")
+	xs := strings.Split(strings.Trim(path, "/"), "/")
+
+	if len(ps) != len(xs) {
+		return nil, false
+	}
+
+	vars := map[string]string{}
+
+	for i, p := range ps {
+		if strings.HasPrefix(p, ":") {
+			vars[p[1:]] = xs[i]
+			continue
+		}
+
+		if p != xs[i] {
+			return nil, false
+		}
+	}
+
+	return vars, true
+}
+`
+
+const goServerHelpers = `func parseParam(s string, v interface{}) error {
+	var err error
+
+	switch x := v.(type) {
+	case *string:
+		*x = s
+	case *float64:
+		*x, err = strconv.ParseFloat(s, 64)
+	case *bool:
+		*x, err = strconv.ParseBool(s)
+	case *[]string:
+		*x = strings.Split(s, ",")
+	}
+
+	return err
+}
+
+func requireFields(data []byte, keys ...string) error {
+	m := map[string]json.RawMessage{}
+
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("invalid body: %s", err)
+	}
+
+	for _, k := range keys {
+		if _, ok := m[k]; !ok {
+			return fmt.Errorf("missing required field %s", k)
+		}
+	}
+
+	return nil
+}
+`
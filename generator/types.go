@@ -0,0 +1,429 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Type describes shape of message body. Kind is one of object, array,
+// string, number, integer, boolean, any, or text for non-JSON bodies.
+type Type struct {
+	Name        string
+	Kind        string
+	Description string
+	Fields      []Field
+	Elem        *Type
+	Enum        []string
+}
+
+// Field is a property of object type
+type Field struct {
+	Key         string
+	Name        string
+	Type        *Type
+	Required    bool
+	Description string
+}
+
+type schemaNode struct {
+	Type        interface{}                `json:"type"`
+	Ref         string                     `json:"$ref"`
+	Description string                     `json:"description"`
+	Properties  json.RawMessage            `json:"properties"`
+	Required    []string                   `json:"required"`
+	Items       json.RawMessage            `json:"items"`
+	Enum        []interface{}              `json:"enum"`
+	Definitions map[string]json.RawMessage `json:"definitions"`
+	OneOf       []json.RawMessage          `json:"oneOf"`
+	AnyOf       []json.RawMessage          `json:"anyOf"`
+}
+
+// typeBuilder builds types of schema. Definitions are built once, so every
+// reference to a definition, recursive ones included, shares its type.
+type typeBuilder struct {
+	definitions map[string]json.RawMessage
+	refs        map[string]*Type
+	building    map[*Type]bool
+	depth       int
+}
+
+// NewType builds type named name from JSON schema. When schema is missing or
+// invalid, type is inferred from JSON example instead.
+func NewType(name, schema, example string) *Type {
+	if s := strings.TrimSpace(schema); s != "" {
+		var root schemaNode
+
+		if err := json.Unmarshal([]byte(s), &root); err == nil {
+			b := &typeBuilder{definitions: root.Definitions, refs: map[string]*Type{}, building: map[*Type]bool{}}
+			return b.fromSchema(name, json.RawMessage(s))
+		}
+	}
+
+	s := strings.TrimSpace(example)
+
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return &Type{Name: name, Kind: "text"}
+	}
+
+	return fromExample(name, json.RawMessage(s))
+}
+
+func (b *typeBuilder) fromSchema(name string, raw json.RawMessage) *Type {
+	var n schemaNode
+
+	if err := json.Unmarshal(raw, &n); err != nil || b.depth > 32 {
+		return &Type{Name: name, Kind: "any"}
+	}
+
+	b.depth++
+	defer func() { b.depth-- }()
+
+	if n.Ref != "" {
+		return b.ref(name, n.Ref)
+	}
+
+	t := &Type{Name: name}
+	b.build(t, n)
+
+	return t
+}
+
+// ref returns type of definition referred by ref. Definitions which refer
+// back to themselves other than through object fields, e.g. arrays of
+// themselves, have no Go or TypeScript declaration, so such references are
+// typed any.
+func (b *typeBuilder) ref(name, ref string) *Type {
+	key := strings.TrimPrefix(ref, "#/definitions/")
+
+	if t, ok := b.refs[key]; ok {
+		if b.building[t] && t.Kind != "object" {
+			return &Type{Name: name, Kind: "any"}
+		}
+
+		return t
+	}
+
+	var n schemaNode
+
+	if err := json.Unmarshal(b.definitions[key], &n); err != nil {
+		return &Type{Name: name, Kind: "any"}
+	}
+
+	t := &Type{Name: Identifier(key)}
+	b.refs[key] = t
+
+	if n.Ref != "" {
+		b.building[t] = true
+		x := b.ref(name, n.Ref)
+		delete(b.building, t)
+
+		b.refs[key] = x
+		return x
+	}
+
+	b.building[t] = true
+	b.build(t, n)
+	delete(b.building, t)
+
+	return t
+}
+
+// build fills t, named already, from schema node n
+func (b *typeBuilder) build(t *Type, n schemaNode) {
+	name := t.Name
+	t.Kind = schemaKind(n)
+	t.Description = n.Description
+
+	for _, e := range n.Enum {
+		if s, ok := e.(string); ok {
+			t.Enum = append(t.Enum, s)
+		}
+	}
+
+	switch t.Kind {
+	case "object":
+		required := map[string]bool{}
+		for _, k := range n.Required {
+			required[k] = true
+		}
+
+		props := map[string]json.RawMessage{}
+		json.Unmarshal(n.Properties, &props)
+
+		fs := newFieldSet()
+		for _, k := range orderedKeys(n.Properties) {
+			ft := b.fromSchema(name+Identifier(k), props[k])
+			fs.add(k, ft, required[k])
+		}
+
+		t.Fields = fs.fields
+	case "array":
+		items := n.Items
+		if bytes.HasPrefix(bytes.TrimSpace(items), []byte("[")) {
+			xs := []json.RawMessage{}
+			json.Unmarshal(items, &xs)
+
+			items = nil
+			if len(xs) > 0 {
+				items = xs[0]
+			}
+		}
+
+		if len(items) == 0 {
+			t.Elem = &Type{Name: name + "Item", Kind: "any"}
+		} else {
+			t.Elem = b.fromSchema(name+"Item", items)
+		}
+	}
+}
+
+func schemaKind(n schemaNode) string {
+	switch v := n.Type.(type) {
+	case string:
+		return v
+	case []interface{}:
+		for _, x := range v {
+			if s, ok := x.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+
+	if len(n.Properties) > 0 {
+		return "object"
+	}
+
+	if len(n.Items) > 0 {
+		return "array"
+	}
+
+	return "any"
+}
+
+func fromExample(name string, raw json.RawMessage) *Type {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return &Type{Name: name, Kind: "any"}
+	}
+
+	switch raw[0] {
+	case '{':
+		props := map[string]json.RawMessage{}
+		json.Unmarshal(raw, &props)
+
+		fs := newFieldSet()
+		for _, k := range orderedKeys(raw) {
+			fs.add(k, fromExample(name+Identifier(k), props[k]), false)
+		}
+
+		return &Type{Name: name, Kind: "object", Fields: fs.fields}
+	case '[':
+		xs := []json.RawMessage{}
+		json.Unmarshal(raw, &xs)
+
+		t := &Type{Name: name, Kind: "array", Elem: &Type{Name: name + "Item", Kind: "any"}}
+		if len(xs) > 0 {
+			t.Elem = fromExample(name+"Item", xs[0])
+		}
+
+		return t
+	case '"':
+		return &Type{Name: name, Kind: "string"}
+	case 't', 'f':
+		return &Type{Name: name, Kind: "boolean"}
+	case 'n':
+		return &Type{Name: name, Kind: "any"}
+	}
+
+	if bytes.ContainsAny(raw, ".eE") {
+		return &Type{Name: name, Kind: "number"}
+	}
+
+	return &Type{Name: name, Kind: "integer"}
+}
+
+// orderedKeys returns keys of JSON object in order of appearance
+func orderedKeys(raw json.RawMessage) []string {
+	ks := []string{}
+
+	if len(raw) == 0 {
+		return ks
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return ks
+	}
+
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return ks
+		}
+
+		k, ok := t.(string)
+		if !ok {
+			return ks
+		}
+
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			return ks
+		}
+
+		ks = append(ks, k)
+	}
+
+	return ks
+}
+
+type fieldSet struct {
+	fields []Field
+	names  map[string]int
+}
+
+func newFieldSet() *fieldSet {
+	return &fieldSet{names: map[string]int{}}
+}
+
+func (fs *fieldSet) add(key string, t *Type, required bool) {
+	name := Identifier(key)
+
+	if n := fs.names[name]; n > 0 {
+		fs.names[name] = n + 1
+		name = name + strconv.Itoa(n+1)
+	} else {
+		fs.names[name] = 1
+	}
+
+	fs.fields = append(fs.fields, Field{
+		Key:         key,
+		Name:        name,
+		Type:        t,
+		Required:    required,
+		Description: t.Description,
+	})
+}
+
+// nameTypes gives distinct names to declared types of operations which are
+// named alike but differ in shape, suffixing later ones as fields are. Types
+// of same name and shape keep sharing declaration. Names generated code
+// declares otherwise are suffixed as well.
+func nameTypes(ops []*Operation) {
+	used := map[string]bool{}
+
+	for _, name := range reservedTypes {
+		used[name] = true
+	}
+
+	for _, op := range ops {
+		used[op.Name+"Params"] = true
+		used[op.Name+"Response"] = true
+		used[op.Name+"Writer"] = true
+
+		for _, r := range op.Failures() {
+			used[op.Name+"Error"+strconv.Itoa(r.StatusCode)] = true
+		}
+	}
+
+	names := map[string]string{}
+	visited := map[*Type]bool{}
+
+	var visit func(t *Type)
+	visit = func(t *Type) {
+		if t == nil || visited[t] {
+			return
+		}
+
+		visited[t] = true
+		key := t.Name + "\x00" + t.signature()
+
+		name, ok := names[key]
+		if !ok {
+			name = t.Name
+
+			for n := 2; used[name]; n++ {
+				name = t.Name + strconv.Itoa(n)
+			}
+
+			used[name] = true
+			names[key] = name
+		}
+
+		for _, n := range t.nested() {
+			visit(n)
+		}
+
+		t.Name = name
+	}
+
+	for _, op := range ops {
+		visit(op.Request)
+
+		for _, r := range op.Responses {
+			visit(r.Type)
+		}
+	}
+}
+
+// nested returns object types declared along with t
+func (t *Type) nested() []*Type {
+	switch t.Kind {
+	case "object":
+		xs := []*Type{}
+
+		for _, f := range t.Fields {
+			xs = append(xs, objectTypes(f.Type)...)
+		}
+
+		return xs
+	case "array":
+		return objectTypes(t.Elem)
+	}
+
+	return nil
+}
+
+// signature describes shape of t, including names of object types it refers
+// to
+func (t *Type) signature() string {
+	return t.shape(map[*Type]bool{})
+}
+
+// shape describes t, referring object types of stack, which t is nested
+// in, by name only
+func (t *Type) shape(stack map[*Type]bool) string {
+	if t == nil {
+		return ""
+	}
+
+	if stack[t] {
+		return "^" + t.Name
+	}
+
+	stack[t] = true
+	defer delete(stack, t)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s%q", t.Kind, t.Enum)
+
+	switch t.Kind {
+	case "object":
+		b.WriteString("{")
+
+		for _, f := range t.Fields {
+			fmt.Fprintf(&b, "%q %s %t %s:%s;", f.Key, f.Name, f.Required, f.Type.Name, f.Type.shape(stack))
+		}
+
+		b.WriteString("}")
+	case "array":
+		fmt.Fprintf(&b, "[%s:%s]", t.Elem.Name, t.Elem.shape(stack))
+	}
+
+	return b.String()
+}
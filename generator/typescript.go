@@ -0,0 +1,414 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/subosito/snowboard/api"
+)
+
+// tsWriter accumulates TypeScript source code
+type tsWriter struct {
+	bytes.Buffer
+	declared map[string]bool
+}
+
+func newTSWriter() *tsWriter {
+	return &tsWriter{declared: map[string]bool{}}
+}
+
+func (w *tsWriter) p(format string, args ...interface{}) {
+	fmt.Fprintf(w, format, args...)
+	w.WriteByte('\n')
+}
+
+func (w *tsWriter) raw(s string) {
+	w.WriteString(s)
+	w.WriteByte('\n')
+}
+
+func (w *tsWriter) comment(prefix, s string) {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+
+	if len(lines) == 1 {
+		w.p("%s/** %s */", prefix, strings.TrimSpace(strings.Replace(lines[0], "*/", "* /", -1)))
+		return
+	}
+
+	w.p("%s/**", prefix)
+	for _, line := range lines {
+		w.p("%s * %s", prefix, strings.TrimSpace(strings.Replace(line, "*/", "* /", -1)))
+	}
+	w.p("%s */", prefix)
+}
+
+// TypeScript generates TypeScript module containing interfaces of message
+// bodies, union of documented responses of each action and fetch-based client
+func TypeScript(b *api.API) ([]byte, error) {
+	w := newTSWriter()
+	ops := Operations(b)
+
+	w.p("// Code generated by snowboard. DO NOT EDIT.")
+	w.p("")
+
+	if b.Title != "" {
+		w.comment("", "Client of "+b.Title)
+		w.p("")
+	}
+
+	w.p("export const DEFAULT_BASE_URL = %s;", strconv.Quote(strings.TrimSuffix(b.Host(), "/")))
+	w.p("")
+	w.raw(tsRuntime)
+
+	for _, op := range ops {
+		w.declareType(op.Request)
+
+		for _, r := range op.Responses {
+			w.declareType(r.Type)
+		}
+
+		writeTSOperationTypes(w, op)
+	}
+
+	w.p("export class Client {")
+	w.raw(tsClientBase)
+
+	for _, op := range ops {
+		writeTSOperation(w, op)
+	}
+
+	w.p("}")
+
+	return w.Bytes(), nil
+}
+
+// declareType writes type declaration of t and its nested object types
+func (w *tsWriter) declareType(t *Type) {
+	if t == nil || w.declared[t.Name] {
+		return
+	}
+
+	w.declared[t.Name] = true
+	nested := []*Type{}
+
+	if t.Description != "" {
+		w.comment("", t.Description)
+	}
+
+	switch t.Kind {
+	case "object":
+		w.p("export interface %s {", t.Name)
+
+		for _, f := range t.Fields {
+			if f.Description != "" {
+				w.comment("  ", f.Description)
+			}
+
+			optional := ""
+			if !f.Required {
+				optional = "?"
+			}
+
+			w.p("  %s%s: %s;", tsProperty(f.Key), optional, tsType(f.Type))
+			nested = append(nested, objectTypes(f.Type)...)
+		}
+
+		w.p("}")
+	case "array":
+		w.p("export type %s = %s;", t.Name, tsType(&Type{Kind: "array", Elem: t.Elem}))
+		nested = append(nested, objectTypes(t.Elem)...)
+	default:
+		w.p("export type %s = %s;", t.Name, tsType(&Type{Kind: t.Kind, Enum: t.Enum}))
+	}
+
+	w.p("")
+
+	for _, n := range nested {
+		w.declareType(n)
+	}
+}
+
+// tsType returns TypeScript type expression of t, objects are referred by name
+func tsType(t *Type) string {
+	if t == nil {
+		return "any"
+	}
+
+	switch t.Kind {
+	case "object":
+		return t.Name
+	case "array":
+		elem := tsType(t.Elem)
+		if strings.Contains(elem, "|") {
+			elem = "(" + elem + ")"
+		}
+
+		return elem + "[]"
+	case "string", "text":
+		if len(t.Enum) > 0 {
+			xs := []string{}
+			for _, e := range t.Enum {
+				xs = append(xs, strconv.Quote(e))
+			}
+
+			return strings.Join(xs, " | ")
+		}
+
+		return "string"
+	case "number", "integer":
+		return "number"
+	case "boolean":
+		return "boolean"
+	}
+
+	return "any"
+}
+
+// tsParamType returns TypeScript type of path or query parameter
+func tsParamType(p Param) string {
+	switch p.Kind {
+	case "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		return "string[]"
+	}
+
+	return "string"
+}
+
+var tsIdentifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsProperty returns property name of key, quoted when it's not an identifier
+func tsProperty(key string) string {
+	if tsIdentifierPattern.MatchString(key) {
+		return key
+	}
+
+	return strconv.Quote(key)
+}
+
+// tsAccess returns property access expression of key on object obj
+func tsAccess(obj, key string) string {
+	if tsIdentifierPattern.MatchString(key) {
+		return obj + "." + key
+	}
+
+	return obj + "[" + strconv.Quote(key) + "]"
+}
+
+func writeTSOperationTypes(w *tsWriter, op *Operation) {
+	params := append([]Param{}, op.PathParams...)
+	params = append(params, op.QueryParams...)
+
+	if len(params) > 0 {
+		w.comment("", "Path and query parameters of "+op.Name)
+		w.p("export interface %sParams {", op.Name)
+
+		for _, p := range params {
+			if p.Description != "" {
+				w.comment("  ", p.Description)
+			}
+
+			optional := ""
+			if !p.Required {
+				optional = "?"
+			}
+
+			w.p("  %s%s: %s;", tsProperty(p.Key), optional, tsParamType(p))
+		}
+
+		w.p("}")
+		w.p("")
+	}
+
+	xs := []string{}
+
+	for _, r := range op.Responses {
+		if r.Type == nil {
+			xs = append(xs, fmt.Sprintf("{ status: %d }", r.StatusCode))
+		} else {
+			xs = append(xs, fmt.Sprintf("{ status: %d; body: %s }", r.StatusCode, r.Type.Name))
+		}
+	}
+
+	if len(xs) == 0 {
+		xs = append(xs, "never")
+	}
+
+	w.comment("", "Documented responses of "+op.Name)
+	w.p("export type %sResponse =", op.Name)
+
+	for i, x := range xs {
+		end := ""
+		if i == len(xs)-1 {
+			end = ";"
+		}
+
+		w.p("  | %s%s", x, end)
+	}
+
+	w.p("")
+}
+
+func writeTSOperation(w *tsWriter, op *Operation) {
+	args := []string{}
+	hasParams := len(op.PathParams)+len(op.QueryParams) > 0
+
+	if hasParams {
+		required := len(op.PathParams) > 0
+		for _, p := range op.QueryParams {
+			required = required || p.Required
+		}
+
+		if required {
+			args = append(args, "params: "+op.Name+"Params")
+		} else {
+			args = append(args, "params: "+op.Name+"Params = {}")
+		}
+	}
+
+	if op.Request != nil {
+		args = append(args, "body: "+op.Request.Name)
+	}
+
+	args = append(args, "init?: RequestInit")
+
+	title := op.Title
+	if title == "" {
+		title = op.Method + " " + op.Path
+	}
+
+	w.p("")
+
+	doc := "Performs " + title
+	if op.Description != "" {
+		doc += "\n\n" + op.Description
+	}
+
+	w.comment("  ", doc)
+	w.p("  async %s(%s): Promise<%sResponse> {", lowerIdentifier(op.Name), strings.Join(args, ", "), op.Name)
+
+	path := pathExpr(op.Path, func(key string, escape bool) string {
+		v := "formatParam(" + tsAccess("params", key) + ")"
+		if escape {
+			v = "encodeURIComponent(" + v + ")"
+		}
+
+		return v
+	})
+
+	query := []string{}
+	for _, p := range op.QueryParams {
+		query = append(query, fmt.Sprintf("%s: %s", tsProperty(p.Key), tsAccess("params", p.Key)))
+	}
+
+	contentType := ""
+	payload := "undefined"
+
+	if op.Request != nil {
+		contentType = op.ContentType
+		payload = "body"
+
+		if op.Request.Kind != "text" {
+			if contentType == "" {
+				contentType = "application/json"
+			}
+
+			payload = "JSON.stringify(body)"
+		}
+	}
+
+	q := "{}"
+	if len(query) > 0 {
+		q = "{ " + strings.Join(query, ", ") + " }"
+	}
+
+	w.p("    const res = await this.request(%s, %s, %s, %s, %s, init);", strconv.Quote(op.Method), path, q, strconv.Quote(contentType), payload)
+	w.p("")
+	w.p("    switch (res.status) {")
+
+	for _, r := range op.Responses {
+		w.p("      case %d:", r.StatusCode)
+
+		switch {
+		case r.Type == nil:
+			w.p("        return { status: %d };", r.StatusCode)
+		case r.Type.Kind == "text":
+			w.p("        return { status: %d, body: res.text };", r.StatusCode)
+		default:
+			w.p("        return { status: %d, body: decode(res.text) };", r.StatusCode)
+		}
+	}
+
+	w.p("    }")
+	w.p("")
+	w.p("    throw new ApiError(res.status, res.text);")
+	w.p("  }")
+}
+
+const tsRuntime = `/** Thrown when API responds with undocumented status code */
+export class ApiError extends Error {
+  status: number;
+  body: string;
+
+  constructor(status: number, body: string) {
+    super("unexpected status code " + status + ": " + body);
+    this.status = status;
+    this.body = body;
+  }
+}
+
+type Param = string | number | boolean | string[];
+
+function formatParam(v: Param): string {
+  return Array.isArray(v) ? v.join(",") : String(v);
+}
+
+function decode(text: string): any {
+  return text === "" ? undefined : JSON.parse(text);
+}
+`
+
+const tsClientBase = `  baseURL: string;
+  init: RequestInit;
+
+  /** Returns client of API hosted on baseURL, init is applied to every request */
+  constructor(baseURL: string = DEFAULT_BASE_URL, init: RequestInit = {}) {
+    this.baseURL = baseURL.replace(/\/+$/, "");
+    this.init = init;
+  }
+
+  protected async request(
+    method: string,
+    path: string,
+    query: { [key: string]: Param | undefined },
+    contentType: string,
+    body: string | undefined,
+    init?: RequestInit
+  ): Promise<{ status: number; text: string }> {
+    const q: string[] = [];
+
+    for (const k of Object.keys(query)) {
+      const v = query[k];
+      if (v !== undefined) {
+        q.push(encodeURIComponent(k) + "=" + encodeURIComponent(formatParam(v)));
+      }
+    }
+
+    const headers = new Headers(this.init.headers);
+    new Headers(init && init.headers).forEach((v, k) => headers.set(k, v));
+
+    if (contentType !== "") {
+      headers.set("Content-Type", contentType);
+    }
+
+    const url = this.baseURL + path + (q.length > 0 ? "?" + q.join("&") : "");
+    const res = await fetch(url, { ...this.init, ...init, method, headers, body });
+
+    return { status: res.status, text: await res.text() };
+  }`
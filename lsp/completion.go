@@ -0,0 +1,140 @@
+package lsp
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	methodNames = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+
+	statusCodes = []int{200, 201, 202, 204, 301, 302, 304, 400, 401, 403, 404, 405, 409, 410, 412, 415, 422, 429, 500, 502, 503, 504}
+
+	contentTypes = []string{
+		"application/json",
+		"application/hal+json",
+		"application/problem+json",
+		"application/xml",
+		"application/x-www-form-urlencoded",
+		"application/octet-stream",
+		"multipart/form-data",
+		"text/plain",
+		"text/html",
+		"text/csv",
+	}
+
+	headerNames = []string{
+		"Accept",
+		"Accept-Language",
+		"Authorization",
+		"Cache-Control",
+		"Content-Length",
+		"Content-Type",
+		"Cookie",
+		"ETag",
+		"If-Match",
+		"If-None-Match",
+		"Last-Modified",
+		"Link",
+		"Location",
+		"Retry-After",
+		"Set-Cookie",
+		"User-Agent",
+		"X-Request-Id",
+	}
+
+	sectionKeywords = []string{"Request", "Response", "Parameters", "Attributes", "Headers", "Body", "Schema", "Relation"}
+)
+
+var (
+	methodContextRe      = regexp.MustCompile(`^#+\s+(?:.*\[)?[A-Z]*$`)
+	statusContextRe      = regexp.MustCompile(`^\s*[+*-]\s+Response\s+\d*$`)
+	contentTypeContextRe = regexp.MustCompile(`^\s*[+*-]\s+(?:Request|Response)\b.*\(\s*[\w./+-]*$`)
+	keywordContextRe     = regexp.MustCompile(`^\s*[+*-]\s+\w*$`)
+	headerNameContextRe  = regexp.MustCompile(`^\s+[\w-]*$`)
+	headerValueContextRe = regexp.MustCompile(`^\s+(?i:content-type|accept):\s*[\w./+-]*$`)
+	headersRe            = regexp.MustCompile(`^\s*[+*-]\s+Headers\s*$`)
+)
+
+// completion suggests HTTP methods in action headings, status codes and
+// content types of requests and responses, header names within headers
+// section and section keywords of list items
+func (s *Server) completion(p positionParams) CompletionList {
+	list := CompletionList{Items: []CompletionItem{}}
+
+	d, ok := s.docs[p.TextDocument.URI]
+	if !ok {
+		return list
+	}
+
+	lines := d.lines()
+	if p.Position.Line >= len(lines) {
+		return list
+	}
+
+	line := lines[p.Position.Line]
+	prefix := line[:byteColumn(line, p.Position.Character)]
+
+	switch {
+	case methodContextRe.MatchString(prefix):
+		for _, m := range methodNames {
+			list.Items = append(list.Items, CompletionItem{Label: m, Kind: CompletionKeyword})
+		}
+	case statusContextRe.MatchString(prefix):
+		for _, code := range statusCodes {
+			list.Items = append(list.Items, CompletionItem{Label: strconv.Itoa(code), Kind: CompletionValue, Detail: http.StatusText(code)})
+		}
+	case contentTypeContextRe.MatchString(prefix):
+		list.Items = contentTypeItems()
+	case inHeaders(lines, p.Position.Line) && headerValueContextRe.MatchString(prefix):
+		list.Items = contentTypeItems()
+	case inHeaders(lines, p.Position.Line) && headerNameContextRe.MatchString(prefix):
+		for _, h := range headerNames {
+			list.Items = append(list.Items, CompletionItem{Label: h, Kind: CompletionProperty})
+		}
+	case keywordContextRe.MatchString(prefix):
+		for _, k := range sectionKeywords {
+			list.Items = append(list.Items, CompletionItem{Label: k, Kind: CompletionKeyword})
+		}
+	}
+
+	return list
+}
+
+func contentTypeItems() []CompletionItem {
+	xs := []CompletionItem{}
+
+	for _, t := range contentTypes {
+		xs = append(xs, CompletionItem{Label: t, Kind: CompletionValue})
+	}
+
+	return xs
+}
+
+// inHeaders tells whether line is within headers section, which are lines
+// indented deeper than "+ Headers" item preceding them
+func inHeaders(lines []string, line int) bool {
+	indent := indentation(lines[line])
+
+	for i := line - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) == "" {
+			continue
+		}
+
+		if headersRe.MatchString(lines[i]) {
+			return indentation(lines[i]) < indent
+		}
+
+		if indentation(lines[i]) < indent {
+			return false
+		}
+	}
+
+	return false
+}
+
+func indentation(s string) int {
+	return len(s) - len(strings.TrimLeft(s, " \t"))
+}
@@ -0,0 +1,271 @@
+package lsp
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/subosito/snowboard/api"
+	snowboard "github.com/subosito/snowboard/parser"
+)
+
+// analyze publishes diagnostics of document, or of document including it
+// when it is a partial
+func (s *Server) analyze(d *document) error {
+	if uri, ok := s.includes[d.path]; ok && uri != d.uri {
+		if x, ok := s.docs[uri]; ok {
+			d = x
+		}
+	}
+
+	ds := s.diagnose(d)
+	uris := []string{}
+
+	for uri := range ds {
+		uris = append(uris, uri)
+	}
+
+	sort.Strings(uris)
+
+	for _, uri := range s.published[d.uri] {
+		if _, ok := ds[uri]; !ok {
+			ds[uri] = []Diagnostic{}
+			uris = append(uris, uri)
+		}
+	}
+
+	s.published[d.uri] = uris
+
+	for _, uri := range uris {
+		if err := s.out.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: uri, Diagnostics: ds[uri]}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// clear removes diagnostics published for closed document
+func (s *Server) clear(uri string) error {
+	uris := s.published[uri]
+	delete(s.published, uri)
+	delete(s.apis, uri)
+
+	for _, x := range uris {
+		if err := s.out.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: x, Diagnostics: []Diagnostic{}}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// diagnose validates document along with partials it includes and checks
+// it against lint rules, returning diagnostics by URI of file they are
+// found in
+func (s *Server) diagnose(d *document) map[string][]Diagnostic {
+	ds := map[string][]Diagnostic{d.uri: {}}
+	lines := d.lines()
+
+	x, err := snowboard.Expand(snowboard.Source{
+		Name:    d.path,
+		BaseDir: filepath.Dir(d.path),
+		Reader:  strings.NewReader(d.text),
+	})
+	if err != nil {
+		ds[d.uri] = append(ds[d.uri], diagnostic(lineRange(lines, 0), SeverityError, "", err.Error()))
+		return ds
+	}
+
+	name, err := filepath.Abs(d.path)
+	if err != nil {
+		name = d.path
+	}
+
+	f := &files{server: s, main: d, name: name, lines: map[string][]string{name: lines}}
+
+	for _, o := range x.Origins {
+		if o.File != name {
+			s.includes[o.File] = d.uri
+			ds[f.uri(o.File)] = []Diagnostic{}
+		}
+	}
+
+	v, err := snowboard.Validate(bytes.NewReader(x.Body), s.engine)
+	if err != nil {
+		ds[d.uri] = append(ds[d.uri], diagnostic(lineRange(lines, 0), SeverityError, "", err.Error()))
+		return ds
+	}
+
+	failed := false
+
+	if v != nil {
+		for _, n := range v.Annotations {
+			severity := SeverityWarning
+			if hasClass(n, "error") {
+				severity, failed = SeverityError, true
+			}
+
+			code := ""
+			if n.Code != 0 {
+				code = strconv.Itoa(n.Code)
+			}
+
+			uri, r := f.locate(x, n)
+			ds[uri] = append(ds[uri], diagnostic(r, severity, code, n.Description))
+		}
+	}
+
+	if failed {
+		return ds
+	}
+
+	b, err := snowboard.Parse(bytes.NewReader(x.Body), s.engine)
+	if err != nil {
+		ds[d.uri] = append(ds[d.uri], diagnostic(lineRange(lines, 0), SeverityError, "", err.Error()))
+		return ds
+	}
+
+	for uri := range ds {
+		s.apis[uri] = b
+	}
+
+	for _, p := range snowboard.Lint(b, s.rules) {
+		severity := SeverityWarning
+		if p.Severity == snowboard.LintError {
+			severity = SeverityError
+		}
+
+		uri, r := f.action(x, p.Method, p.URL)
+		ds[uri] = append(ds[uri], diagnostic(r, severity, p.Rule, p.Message))
+	}
+
+	return ds
+}
+
+func diagnostic(r Range, severity int, code, message string) Diagnostic {
+	return Diagnostic{Range: r, Severity: severity, Code: code, Source: "snowboard", Message: message}
+}
+
+func hasClass(n api.Annotation, class string) bool {
+	for _, c := range n.Classes {
+		if c == class {
+			return true
+		}
+	}
+
+	return false
+}
+
+// files are lines of document and partials it includes, read from editor
+// when opened there or from disk otherwise
+type files struct {
+	server *Server
+	main   *document
+	name   string
+	lines  map[string][]string
+}
+
+func (f *files) get(name string) []string {
+	if xs, ok := f.lines[name]; ok {
+		return xs
+	}
+
+	xs := []string{}
+
+	if d := f.server.find(name); d != nil {
+		xs = d.lines()
+	} else if b, err := ioutil.ReadFile(name); err == nil {
+		xs = splitLines(string(b))
+	}
+
+	f.lines[name] = xs
+
+	return xs
+}
+
+func (f *files) uri(name string) string {
+	if name == f.name {
+		return f.main.uri
+	}
+
+	if d := f.server.find(name); d != nil {
+		return d.uri
+	}
+
+	return pathURI(name)
+}
+
+// locate maps first source map of annotation, given as byte offset and
+// length of expanded blueprint, to range of file it comes from
+func (f *files) locate(x *snowboard.Expansion, n api.Annotation) (string, Range) {
+	if len(n.SourceMaps) == 0 {
+		return f.main.uri, lineRange(f.get(f.name), 0)
+	}
+
+	m := n.SourceMaps[0]
+	o, col := x.Locate(m.Row)
+	lines := f.get(o.File)
+
+	r := lineRange(lines, o.Line)
+	if o.Line < len(lines) {
+		r.Start.Character = character(lines[o.Line], col)
+	}
+
+	e, ecol := x.Locate(m.Row + m.Col)
+	if e.File == o.File && e.Line >= o.Line && e.Line < len(lines) {
+		r.End = Position{Line: e.Line, Character: character(lines[e.Line], ecol)}
+	}
+
+	return f.uri(o.File), r
+}
+
+// action finds heading of action by method and URL, which ends with URI
+// template of action or its resource, falling back to first line of
+// document
+func (f *files) action(x *snowboard.Expansion, method, url string) (string, Range) {
+	uri, r := f.main.uri, lineRange(f.get(f.name), 0)
+
+	if method == "" {
+		return uri, r
+	}
+
+	seen := map[string]bool{}
+	best := -1
+
+	for _, o := range append([]snowboard.Origin{{File: f.name}}, x.Origins...) {
+		if seen[o.File] {
+			continue
+		}
+
+		seen[o.File] = true
+		lines := f.get(o.File)
+
+		for _, h := range outline(lines) {
+			if h.kind != SymbolMethod || h.method != method || h.uri == "" || !strings.HasSuffix(url, h.uri) {
+				continue
+			}
+
+			if len(h.uri) > best {
+				best = len(h.uri)
+				uri, r = f.uri(o.File), lineRange(lines, h.line)
+			}
+		}
+	}
+
+	return uri, r
+}
+
+// find returns open document of path
+func (s *Server) find(name string) *document {
+	for _, d := range s.docs {
+		if d.path == name {
+			return d
+		}
+	}
+
+	return nil
+}
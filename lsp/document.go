@@ -0,0 +1,132 @@
+package lsp
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// document is a text document opened in editor
+type document struct {
+	uri  string
+	path string
+	text string
+}
+
+func newDocument(uri, text string) *document {
+	return &document{uri: uri, path: uriPath(uri), text: text}
+}
+
+func (d *document) lines() []string {
+	return splitLines(d.text)
+}
+
+// offset returns byte offset of position
+func (d *document) offset(p Position) int {
+	n := 0
+
+	for i, line := range strings.SplitAfter(d.text, "\n") {
+		if i == p.Line {
+			return n + byteColumn(strings.TrimRight(line, "\r\n"), p.Character)
+		}
+
+		n += len(line)
+	}
+
+	return len(d.text)
+}
+
+// apply applies content change sent by editor
+func (d *document) apply(c contentChange) {
+	if c.Range == nil {
+		d.text = c.Text
+		return
+	}
+
+	start, end := d.offset(c.Range.Start), d.offset(c.Range.End)
+	if end < start {
+		end = start
+	}
+
+	d.text = d.text[:start] + c.Text + d.text[end:]
+}
+
+func splitLines(s string) []string {
+	xs := strings.Split(s, "\n")
+
+	for i := range xs {
+		xs[i] = strings.TrimSuffix(xs[i], "\r")
+	}
+
+	return xs
+}
+
+// utf16Len returns length of s in UTF-16 code units, as positions count
+func utf16Len(s string) int {
+	n := 0
+
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+
+	return n
+}
+
+// byteColumn returns byte offset of UTF-16 character offset within line
+func byteColumn(line string, character int) int {
+	n := 0
+
+	for i, r := range line {
+		if n >= character {
+			return i
+		}
+
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+
+	return len(line)
+}
+
+// character returns UTF-16 character offset of byte column within line
+func character(line string, column int) int {
+	if column >= len(line) {
+		return utf16Len(line)
+	}
+
+	for column > 0 && !utf8.RuneStart(line[column]) {
+		column--
+	}
+
+	return utf16Len(line[:column])
+}
+
+func lineRange(lines []string, line int) Range {
+	end := 0
+	if line >= 0 && line < len(lines) {
+		end = utf16Len(lines[line])
+	}
+
+	return Range{Start: Position{Line: line}, End: Position{Line: line, Character: end}}
+}
+
+func uriPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+
+	return filepath.FromSlash(u.Path)
+}
+
+func pathURI(name string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(name)}).String()
+}
@@ -0,0 +1,141 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidParams  = -32602
+	codeMethodNotFound = -32601
+	codeInvalidRequest = -32600
+)
+
+type request struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  *json.RawMessage `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *responseError) Error() string {
+	return e.Message
+}
+
+type notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+// readMessage reads content of message framed by Content-Length header
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+
+		i := strings.Index(line, ":")
+		if i == -1 {
+			return nil, fmt.Errorf("Unable to read message header %q", line)
+		}
+
+		if strings.EqualFold(strings.TrimSpace(line[:i]), "Content-Length") {
+			n, err := strconv.Atoi(strings.TrimSpace(line[i+1:]))
+			if err != nil {
+				return nil, fmt.Errorf("Unable to read message header %q", line)
+			}
+
+			length = n
+		}
+	}
+
+	if length < 0 {
+		return nil, fmt.Errorf("Unable to read message: missing Content-Length header")
+	}
+
+	b := make([]byte, length)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// writer writes framed messages, one at a time
+type writer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *writer) write(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, err := fmt.Fprintf(w.w, "Content-Length: %d\r\n\r\n", len(b)); err != nil {
+		return err
+	}
+
+	_, err = w.w.Write(b)
+	return err
+}
+
+func (w *writer) reply(id *json.RawMessage, result interface{}, err error) error {
+	res := response{JSONRPC: "2.0", ID: id}
+
+	if err != nil {
+		e, ok := err.(*responseError)
+		if !ok {
+			e = &responseError{Code: codeInvalidRequest, Message: err.Error()}
+		}
+
+		res.Error = e
+		return w.write(res)
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	raw := json.RawMessage(b)
+	res.Result = &raw
+
+	return w.write(res)
+}
+
+func (w *writer) notify(method string, params interface{}) error {
+	return w.write(notification{JSONRPC: "2.0", Method: method, Params: params})
+}
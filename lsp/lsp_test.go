@@ -0,0 +1,319 @@
+package lsp_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/subosito/snowboard/lsp"
+)
+
+// engine is a parser returning fixed blueprint, warning about heading of
+// DELETE action wherever it is found in blueprint
+type engine struct{}
+
+func (engine) Parse(r io.Reader) ([]byte, error) {
+	return []byte(`{"element": "parseResult", "content": [{"element": "category", "meta": {"classes": ["api"], "title": "Notes API"}, "content": [
+		{"element": "category", "meta": {"classes": ["resourceGroup"], "title": "Notes"}, "content": [
+			{"element": "resource", "meta": {"title": "Note"}, "attributes": {"href": "/notes/{id}"}, "content": [
+				{"element": "transition", "meta": {"title": "Delete a Note"}, "content": [
+					{"element": "httpTransaction", "content": [
+						{"element": "httpRequest", "attributes": {"method": "DELETE"}},
+						{"element": "httpResponse", "attributes": {"statusCode": "204"}}
+					]}
+				]}
+			]}
+		]}
+	]}]}`), nil
+}
+
+func (engine) Validate(r io.Reader) ([]byte, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	h := "### Delete a Note [DELETE]"
+	i := bytes.Index(b, []byte(h))
+	if i == -1 {
+		return []byte(`{"element": "parseResult", "content": []}`), nil
+	}
+
+	return []byte(fmt.Sprintf(`{"element": "parseResult", "content": [{"element": "annotation", "meta": {"classes": ["warning"]}, "attributes": {"code": 6, "sourceMap": [{"element": "sourceMap", "content": [[%d, %d]]}]}, "content": "action is deprecated"}]}`, i, len(h))), nil
+}
+
+func (engine) Version() string {
+	return "test"
+}
+
+type message struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code int `json:"code"`
+	} `json:"error"`
+}
+
+type session struct {
+	in   bytes.Buffer
+	next int
+}
+
+func (s *session) send(method string, params interface{}) int {
+	s.next++
+	s.write(map[string]interface{}{"jsonrpc": "2.0", "id": s.next, "method": method, "params": params})
+	return s.next
+}
+
+func (s *session) notify(method string, params interface{}) {
+	s.write(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+func (s *session) write(v interface{}) {
+	b, _ := json.Marshal(v)
+	fmt.Fprintf(&s.in, "Content-Length: %d\r\n\r\n%s", len(b), b)
+}
+
+func (s *session) open(uri, text string) {
+	s.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": "apiblueprint", "version": 1, "text": text},
+	})
+}
+
+func (s *session) at(method, uri string, line, character int) int {
+	return s.send(method, map[string]interface{}{
+		"textDocument": map[string]string{"uri": uri},
+		"position":     map[string]int{"line": line, "character": character},
+	})
+}
+
+// run serves session until it ends, returning responses by id along with
+// notifications
+func (s *session) run(t *testing.T, rules map[string]string) (map[int]message, []message) {
+	s.send("shutdown", nil)
+	s.notify("exit", nil)
+
+	var out bytes.Buffer
+	assert.Nil(t, lsp.NewServer(engine{}, rules).Serve(&s.in, &out))
+
+	res, ns := map[int]message{}, []message{}
+
+	for out.Len() > 0 {
+		header, err := out.ReadString('\n')
+		assert.Nil(t, err)
+		out.ReadString('\n')
+
+		n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "Content-Length:")))
+		assert.Nil(t, err)
+
+		var m message
+		assert.Nil(t, json.Unmarshal(out.Next(n), &m))
+
+		if m.ID != nil {
+			res[*m.ID] = m
+		} else {
+			ns = append(ns, m)
+		}
+	}
+
+	return res, ns
+}
+
+const mainBlueprint = `FORMAT: 1A
+
+# Notes API
+
+## Group Notes
+
+<!-- include(notes.apib) -->
+
+# Data Structures
+
+## Note (object)
+
++ id: 1 (number)
+`
+
+const partialBlueprint = `## Note [/notes/{id}]
+
+### Delete a Note [DELETE]
+
++ Response 204
+
+### Retrieve a Note [GET]
+
++ Response 200 (application/json)
+
+    + Attributes (Note)
+`
+
+func project(t *testing.T) (string, string, func()) {
+	dir, err := ioutil.TempDir("", "lsp")
+	assert.Nil(t, err)
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "API.apib"), []byte("FORMAT: 1A\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "notes.apib"), []byte(partialBlueprint), 0644))
+
+	return "file://" + filepath.Join(dir, "API.apib"), "file://" + filepath.Join(dir, "notes.apib"), func() { os.RemoveAll(dir) }
+}
+
+func TestServer_lifecycle(t *testing.T) {
+	s := &session{}
+	initialize := s.send("initialize", map[string]interface{}{"capabilities": map[string]interface{}{}})
+	unknown := s.send("workspace/symbol", map[string]interface{}{})
+	s.notify("$/cancelRequest", map[string]int{"id": 1})
+
+	res, ns := s.run(t, nil)
+	assert.Empty(t, ns)
+
+	var x struct {
+		Capabilities map[string]interface{} `json:"capabilities"`
+	}
+	assert.Nil(t, json.Unmarshal(res[initialize].Result, &x))
+	assert.Equal(t, true, x.Capabilities["hoverProvider"])
+	assert.Equal(t, true, x.Capabilities["documentFormattingProvider"])
+
+	assert.Equal(t, -32601, res[unknown].Error.Code)
+	assert.Equal(t, "null", string(res[s.next].Result))
+
+	s = &session{}
+	s.notify("exit", nil)
+	assert.NotNil(t, lsp.NewServer(engine{}, nil).Serve(&s.in, ioutil.Discard))
+}
+
+func TestServer_diagnostics(t *testing.T) {
+	main, partial, cleanup := project(t)
+	defer cleanup()
+
+	s := &session{}
+	s.open(main, mainBlueprint)
+
+	_, ns := s.run(t, map[string]string{"api-host": "warning", "uri-parameter": "error"})
+
+	ds := map[string][]lsp.Diagnostic{}
+	for _, n := range ns {
+		assert.Equal(t, "textDocument/publishDiagnostics", n.Method)
+
+		var p struct {
+			URI         string           `json:"uri"`
+			Diagnostics []lsp.Diagnostic `json:"diagnostics"`
+		}
+		assert.Nil(t, json.Unmarshal(n.Params, &p))
+		ds[p.URI] = p.Diagnostics
+	}
+
+	assert.Len(t, ds, 2)
+	assert.Equal(t, []lsp.Diagnostic{
+		{Range: lsp.Range{End: lsp.Position{Character: 10}}, Severity: lsp.SeverityWarning, Code: "api-host", Source: "snowboard", Message: "HOST is not documented"},
+	}, ds[main])
+
+	heading := lsp.Range{Start: lsp.Position{Line: 2}, End: lsp.Position{Line: 2, Character: 26}}
+	assert.Equal(t, []lsp.Diagnostic{
+		{Range: heading, Severity: lsp.SeverityWarning, Code: "6", Source: "snowboard", Message: "action is deprecated"},
+		{Range: heading, Severity: lsp.SeverityError, Code: "uri-parameter", Source: "snowboard", Message: "DELETE /notes/{id}: parameter id is not described"},
+	}, ds[partial])
+}
+
+func TestServer_navigation(t *testing.T) {
+	main, partial, cleanup := project(t)
+	defer cleanup()
+
+	s := &session{}
+	s.open(main, mainBlueprint)
+	s.open(partial, partialBlueprint)
+
+	symbols := s.send("textDocument/documentSymbol", map[string]interface{}{"textDocument": map[string]string{"uri": partial}})
+	include := s.at("textDocument/definition", main, 6, 3)
+	note := s.at("textDocument/definition", partial, 10, 20)
+	hover := s.at("textDocument/hover", partial, 2, 5)
+	missing := s.at("textDocument/hover", partial, 6, 5)
+
+	res, _ := s.run(t, nil)
+
+	var xs []lsp.DocumentSymbol
+	assert.Nil(t, json.Unmarshal(res[symbols].Result, &xs))
+	assert.Len(t, xs, 1)
+	assert.Equal(t, "Note", xs[0].Name)
+	assert.Equal(t, "/notes/{id}", xs[0].Detail)
+	assert.Equal(t, 11, xs[0].Range.End.Line)
+	assert.Len(t, xs[0].Children, 2)
+	assert.Equal(t, "Delete a Note", xs[0].Children[0].Name)
+	assert.Equal(t, "DELETE /notes/{id}", xs[0].Children[0].Detail)
+	assert.Equal(t, 5, xs[0].Children[0].Range.End.Line)
+
+	var ls []lsp.Location
+	assert.Nil(t, json.Unmarshal(res[include].Result, &ls))
+	assert.Equal(t, []lsp.Location{{URI: partial}}, ls)
+
+	assert.Nil(t, json.Unmarshal(res[note].Result, &ls))
+	assert.Equal(t, []lsp.Location{{URI: main, Range: lsp.Range{Start: lsp.Position{Line: 10}, End: lsp.Position{Line: 10, Character: 16}}}}, ls)
+
+	var h lsp.Hover
+	assert.Nil(t, json.Unmarshal(res[hover].Result, &h))
+	assert.Equal(t, "markdown", h.Contents.Kind)
+	assert.Contains(t, h.Contents.Value, "Delete a Note")
+	assert.Contains(t, h.Contents.Value, "204")
+
+	assert.Equal(t, "null", string(res[missing].Result))
+}
+
+func TestServer_completion(t *testing.T) {
+	uri := "file:///tmp/completion.apib"
+	text := "## Note [/notes]\n### List [G\n+ Response 2\n+ Request (app\n    + Headers\n\n            Acc\n+ Re\n"
+
+	s := &session{}
+	s.open(uri, text)
+
+	cases := map[int][]string{
+		s.at("textDocument/completion", uri, 1, 11): {"GET", "DELETE"},
+		s.at("textDocument/completion", uri, 2, 12): {"200", "404"},
+		s.at("textDocument/completion", uri, 3, 14): {"application/json"},
+		s.at("textDocument/completion", uri, 6, 15): {"Accept", "Content-Type"},
+		s.at("textDocument/completion", uri, 7, 4):  {"Response", "Attributes"},
+	}
+	none := s.at("textDocument/completion", uri, 0, 16)
+
+	res, _ := s.run(t, nil)
+
+	for id, labels := range cases {
+		var l lsp.CompletionList
+		assert.Nil(t, json.Unmarshal(res[id].Result, &l))
+
+		xs := []string{}
+		for _, x := range l.Items {
+			xs = append(xs, x.Label)
+		}
+
+		for _, label := range labels {
+			assert.Contains(t, xs, label)
+		}
+	}
+
+	var l lsp.CompletionList
+	assert.Nil(t, json.Unmarshal(res[none].Result, &l))
+	assert.Empty(t, l.Items)
+}
+
+func TestServer_formatting(t *testing.T) {
+	uri := "file:///tmp/format.apib"
+
+	s := &session{}
+	s.open(uri, "# API \r\n\r\n+ Response 200\n\n\n")
+	format := s.send("textDocument/formatting", map[string]interface{}{"textDocument": map[string]string{"uri": uri}})
+
+	res, _ := s.run(t, nil)
+
+	var es []lsp.TextEdit
+	assert.Nil(t, json.Unmarshal(res[format].Result, &es))
+	assert.Equal(t, []lsp.TextEdit{{Range: lsp.Range{End: lsp.Position{Line: 5}}, NewText: "# API\n\n+ Response 200\n"}}, es)
+}
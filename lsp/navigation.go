@@ -0,0 +1,194 @@
+package lsp
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	snowboard "github.com/subosito/snowboard/parser"
+)
+
+// hover renders action whose heading is at position as Markdown
+func (s *Server) hover(p positionParams) *Hover {
+	d, ok := s.docs[p.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+
+	b, ok := s.apis[d.uri]
+	if !ok {
+		return nil
+	}
+
+	lines := d.lines()
+
+	for _, h := range outline(lines) {
+		if h.line != p.Position.Line || h.kind != SymbolMethod {
+			continue
+		}
+
+		best := -1
+		var e *snowboard.Endpoint
+
+		for _, x := range snowboard.Endpoints(b) {
+			t := x.Transition
+			if t.Method != h.method || !strings.HasSuffix(t.URL, h.uri) {
+				continue
+			}
+
+			// prefer action of the same title when resource has several of
+			// same method
+			score := len(h.uri)
+			if t.Title == h.name {
+				score++
+			}
+
+			if score > best {
+				x := x
+				best, e = score, &x
+			}
+		}
+
+		if e == nil {
+			return nil
+		}
+
+		var buf bytes.Buffer
+		if err := snowboard.MarkdownTransition(&buf, e.Transition); err != nil {
+			return nil
+		}
+
+		r := lineRange(lines, h.line)
+
+		return &Hover{Contents: MarkupContent{Kind: "markdown", Value: buf.String()}, Range: &r}
+	}
+
+	return nil
+}
+
+// definition locates partial or seed file referred at position, or heading
+// of named type under cursor
+func (s *Server) definition(p positionParams) []Location {
+	d, ok := s.docs[p.TextDocument.URI]
+	if !ok {
+		return []Location{}
+	}
+
+	lines := d.lines()
+	if p.Position.Line >= len(lines) {
+		return []Location{}
+	}
+
+	line := lines[p.Position.Line]
+
+	if name := reference(line); name != "" {
+		x := name
+		if !filepath.IsAbs(x) {
+			x = filepath.Join(s.baseDir(d), x)
+		}
+
+		if _, err := os.Stat(x); err != nil {
+			return []Location{}
+		}
+
+		return []Location{{URI: pathURI(x)}}
+	}
+
+	name := typeName(line, byteColumn(line, p.Position.Character))
+	if name == "" {
+		return []Location{}
+	}
+
+	cs := s.candidates(d)
+
+	// types of data structures sections take precedence over resources,
+	// which define named types of their attributes
+	for _, kind := range []int{SymbolStruct, SymbolClass} {
+		for _, c := range cs {
+			for _, h := range outline(c.lines) {
+				if h.kind == kind && h.name == name {
+					return []Location{{URI: c.uri, Range: lineRange(c.lines, h.line)}}
+				}
+			}
+		}
+	}
+
+	return []Location{}
+}
+
+// baseDir returns directory partials of document are resolved from, which
+// is directory of document including it when it is a partial
+func (s *Server) baseDir(d *document) string {
+	if uri, ok := s.includes[d.path]; ok {
+		if x, ok := s.docs[uri]; ok {
+			d = x
+		}
+	}
+
+	return filepath.Dir(d.path)
+}
+
+type candidate struct {
+	uri   string
+	lines []string
+}
+
+// candidates lists files named types are looked up in: the document, other
+// open documents, then API blueprints next to the document
+func (s *Server) candidates(d *document) []candidate {
+	cs := []candidate{{uri: d.uri, lines: d.lines()}}
+	seen := map[string]bool{d.path: true}
+
+	uris := []string{}
+	for uri := range s.docs {
+		uris = append(uris, uri)
+	}
+
+	sort.Strings(uris)
+
+	for _, uri := range uris {
+		x := s.docs[uri]
+		if !seen[x.path] {
+			seen[x.path] = true
+			cs = append(cs, candidate{uri: x.uri, lines: x.lines()})
+		}
+	}
+
+	names, _ := filepath.Glob(filepath.Join(s.baseDir(d), "*.apib"))
+
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+
+		b, err := ioutil.ReadFile(name)
+		if err != nil {
+			continue
+		}
+
+		seen[name] = true
+		cs = append(cs, candidate{uri: pathURI(name), lines: splitLines(string(b))})
+	}
+
+	return cs
+}
+
+// typeName returns type name under byte column, as written in type
+// definitions like "(array[Note], required)"
+func typeName(line string, column int) string {
+	if column > len(line) {
+		column = len(line)
+	}
+
+	start := strings.LastIndexAny(line[:column], "([,")
+	end := strings.IndexAny(line[column:], ")],")
+
+	if start == -1 || end == -1 {
+		return ""
+	}
+
+	return strings.TrimSpace(line[start+1 : column+end])
+}
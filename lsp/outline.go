@@ -0,0 +1,222 @@
+package lsp
+
+import (
+	"regexp"
+	"strings"
+)
+
+// heading is a group, resource, action or named type heading of document
+type heading struct {
+	kind   int
+	level  int
+	line   int
+	name   string
+	method string
+	uri    string
+}
+
+const methods = `GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS|LINK|UNLINK|TRACE|CONNECT`
+
+var (
+	headingRe        = regexp.MustCompile(`^(#+)\s+(.*?)\s*#*\s*$`)
+	groupRe          = regexp.MustCompile(`^Group\s+(.+)$`)
+	actionRe         = regexp.MustCompile(`^(.*?)\s*\[(` + methods + `)(?:\s+(\S+))?\]$`)
+	resourceRe       = regexp.MustCompile(`^(.*?)\s*\[(/\S*)\]$`)
+	shorthandRe      = regexp.MustCompile(`^(` + methods + `)\s+(/\S*)$`)
+	dataStructuresRe = regexp.MustCompile(`^Data Structures$`)
+	typeRe           = regexp.MustCompile(`^([^(\[]+?)\s*(?:\(.*\))?$`)
+)
+
+// outline scans headings of API blueprint, skipping fenced code blocks
+func outline(lines []string) []heading {
+	hs := []heading{}
+	fenced := false
+	types := 0
+	resource := ""
+
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+			continue
+		}
+
+		if fenced {
+			continue
+		}
+
+		m := headingRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		h := heading{level: len(m[1]), line: i}
+		text := m[2]
+
+		if types > 0 && h.level <= types {
+			types = 0
+		}
+
+		switch {
+		case dataStructuresRe.MatchString(text):
+			types = h.level
+			h.kind, h.name = SymbolNamespace, text
+		case types > 0:
+			x := typeRe.FindStringSubmatch(text)
+			if x == nil {
+				continue
+			}
+
+			h.kind, h.name = SymbolStruct, x[1]
+		case groupRe.MatchString(text):
+			h.kind, h.name = SymbolNamespace, groupRe.FindStringSubmatch(text)[1]
+		case actionRe.MatchString(text):
+			x := actionRe.FindStringSubmatch(text)
+			h.kind, h.name, h.method, h.uri = SymbolMethod, x[1], x[2], x[3]
+
+			if h.uri == "" {
+				h.uri = resource
+			}
+		case resourceRe.MatchString(text):
+			x := resourceRe.FindStringSubmatch(text)
+			h.kind, h.name, h.uri = SymbolClass, x[1], x[2]
+			resource = h.uri
+		case shorthandRe.MatchString(text):
+			x := shorthandRe.FindStringSubmatch(text)
+			h.kind, h.method, h.uri = SymbolMethod, x[1], x[2]
+			resource = h.uri
+		case strings.HasPrefix(text, "/"):
+			h.kind, h.uri = SymbolClass, text
+			resource = h.uri
+		default:
+			continue
+		}
+
+		hs = append(hs, h)
+	}
+
+	return hs
+}
+
+// title returns name of heading shown in outline
+func (h heading) title() string {
+	switch {
+	case h.name != "":
+		return h.name
+	case h.method != "":
+		return h.method + " " + h.uri
+	}
+
+	return h.uri
+}
+
+func (h heading) detail() string {
+	switch h.kind {
+	case SymbolMethod:
+		if h.name == "" {
+			return ""
+		}
+
+		return h.method + " " + h.uri
+	case SymbolClass:
+		if h.name == "" {
+			return ""
+		}
+
+		return h.uri
+	}
+
+	return ""
+}
+
+// rank orders headings into hierarchy: groups and data structures contain
+// resources or types, resources contain actions
+func (h heading) rank() int {
+	switch h.kind {
+	case SymbolNamespace:
+		return 1
+	case SymbolClass, SymbolStruct:
+		return 2
+	}
+
+	return 3
+}
+
+// symbols arranges headings as hierarchy of document symbols, each ranging
+// up to the next heading of same or higher rank
+func symbols(lines []string) []DocumentSymbol {
+	type node struct {
+		h        heading
+		sym      DocumentSymbol
+		children []*node
+	}
+
+	root := &node{}
+	stack := []*node{root}
+
+	finish := func(n *node, line int) {
+		if line < n.h.line {
+			line = n.h.line
+		}
+
+		n.sym.Range.End = lineRange(lines, line).End
+	}
+
+	for _, h := range outline(lines) {
+		for len(stack) > 1 && stack[len(stack)-1].h.rank() >= h.rank() {
+			finish(stack[len(stack)-1], h.line-1)
+			stack = stack[:len(stack)-1]
+		}
+
+		sel := lineRange(lines, h.line)
+		n := &node{h: h, sym: DocumentSymbol{
+			Name:           h.title(),
+			Detail:         h.detail(),
+			Kind:           h.kind,
+			Range:          sel,
+			SelectionRange: sel,
+		}}
+
+		parent := stack[len(stack)-1]
+		parent.children = append(parent.children, n)
+		stack = append(stack, n)
+	}
+
+	for len(stack) > 1 {
+		finish(stack[len(stack)-1], len(lines)-1)
+		stack = stack[:len(stack)-1]
+	}
+
+	var build func(ns []*node) []DocumentSymbol
+	build = func(ns []*node) []DocumentSymbol {
+		xs := []DocumentSymbol{}
+
+		for _, n := range ns {
+			n.sym.Children = build(n.children)
+			xs = append(xs, n.sym)
+		}
+
+		return xs
+	}
+
+	return build(root.children)
+}
+
+var (
+	commentRefRe  = regexp.MustCompile(`<!-- (partial|include|seed)\((.+)\) -->`)
+	templateRefRe = regexp.MustCompile(`\{\{\s*partial\s+"([^"]+)"\s*\}\}`)
+)
+
+// reference returns name of partial or seed file line refers to
+func reference(line string) string {
+	if strings.HasPrefix(line, "<!--") {
+		if m := commentRefRe.FindStringSubmatch(line); m != nil {
+			return m[2]
+		}
+	}
+
+	if m := templateRefRe.FindStringSubmatch(line); m != nil {
+		return m[1]
+	}
+
+	return ""
+}
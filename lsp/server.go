@@ -0,0 +1,244 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/subosito/snowboard/api"
+	snowboard "github.com/subosito/snowboard/parser"
+)
+
+// Server is a language server of API blueprint documents. Documents are
+// validated by engine and checked against lint rules, given as severity by
+// rule name, whenever they are opened, changed or saved.
+type Server struct {
+	engine snowboard.Parser
+	rules  map[string]string
+	out    *writer
+
+	docs map[string]*document
+	// apis are blueprints last parsed successfully, by URI of document and
+	// of every partial it includes
+	apis map[string]*api.API
+	// includes are URIs of documents including partial, by path of partial
+	includes map[string]string
+	// published are URIs diagnostics were published for, by URI of document
+	published map[string][]string
+
+	shutdown bool
+}
+
+// NewServer creates language server validating documents using engine
+func NewServer(engine snowboard.Parser, rules map[string]string) *Server {
+	return &Server{
+		engine:    engine,
+		rules:     rules,
+		docs:      map[string]*document{},
+		apis:      map[string]*api.API{},
+		includes:  map[string]string{},
+		published: map[string][]string{},
+	}
+}
+
+// Serve reads requests from r and writes responses to w until client asks
+// server to exit or closes r
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.out = &writer{w: w}
+	br := bufio.NewReader(r)
+
+	for {
+		b, err := readMessage(br)
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(b, &req); err != nil {
+			if err := s.out.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return fmt.Errorf("Language server exited before shutdown")
+			}
+
+			return nil
+		}
+
+		result, err := s.handle(req.Method, req.Params)
+
+		if req.ID == nil {
+			continue
+		}
+
+		if err := s.out.reply(req.ID, result, err); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case "initialize":
+		return s.initialize(), nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var p didOpenParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+
+		d := newDocument(p.TextDocument.URI, p.TextDocument.Text)
+		s.docs[d.uri] = d
+
+		return nil, s.analyze(d)
+	case "textDocument/didChange":
+		var p didChangeParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+
+		d, ok := s.docs[p.TextDocument.URI]
+		if !ok {
+			return nil, nil
+		}
+
+		for _, c := range p.ContentChanges {
+			d.apply(c)
+		}
+
+		return nil, s.analyze(d)
+	case "textDocument/didSave":
+		var p didSaveParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+
+		d, ok := s.docs[p.TextDocument.URI]
+		if !ok {
+			return nil, nil
+		}
+
+		if p.Text != nil {
+			d.text = *p.Text
+		}
+
+		return nil, s.analyze(d)
+	case "textDocument/didClose":
+		var p documentParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+
+		delete(s.docs, p.TextDocument.URI)
+
+		return nil, s.clear(p.TextDocument.URI)
+	case "textDocument/documentSymbol":
+		var p documentParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+
+		d, ok := s.docs[p.TextDocument.URI]
+		if !ok {
+			return []DocumentSymbol{}, nil
+		}
+
+		return symbols(d.lines()), nil
+	case "textDocument/definition":
+		var p positionParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+
+		return s.definition(p), nil
+	case "textDocument/hover":
+		var p positionParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+
+		return s.hover(p), nil
+	case "textDocument/completion":
+		var p positionParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+
+		return s.completion(p), nil
+	case "textDocument/formatting":
+		var p documentParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+
+		return s.format(p), nil
+	}
+
+	return nil, &responseError{Code: codeMethodNotFound, Message: "Unknown method " + method}
+}
+
+func (s *Server) initialize() initializeResult {
+	return initializeResult{
+		Capabilities: serverCapabilities{
+			TextDocumentSync: textDocumentSyncOptions{
+				OpenClose: true,
+				Change:    1,
+				Save:      saveOptions{IncludeText: true},
+			},
+			DocumentSymbolProvider: true,
+			DefinitionProvider:     true,
+			HoverProvider:          true,
+			CompletionProvider: completionOptions{
+				TriggerCharacters: []string{"[", "(", " ", ":"},
+			},
+			DocumentFormattingProvider: true,
+		},
+		ServerInfo: serverInfo{Name: "snowboard"},
+	}
+}
+
+func decode(params json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	return nil
+}
+
+// format replaces whole document with its formatted text, if it differs
+func (s *Server) format(p documentParams) []TextEdit {
+	d, ok := s.docs[p.TextDocument.URI]
+	if !ok {
+		return []TextEdit{}
+	}
+
+	text := string(snowboard.Format([]byte(d.text)))
+	if text == d.text {
+		return []TextEdit{}
+	}
+
+	lines := d.lines()
+	last := len(lines) - 1
+
+	return []TextEdit{{
+		Range: Range{
+			End: Position{Line: last, Character: utf16Len(lines[last])},
+		},
+		NewText: text,
+	}}
+}
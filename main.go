@@ -4,23 +4,35 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"text/tabwriter"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/subosito/snowboard/adapter/drafter"
 	"github.com/subosito/snowboard/adapter/drafterc"
+	"github.com/subosito/snowboard/api"
+	"github.com/subosito/snowboard/generator"
+	"github.com/subosito/snowboard/lsp"
 	snowboard "github.com/subosito/snowboard/parser"
 	"github.com/urfave/cli"
 )
 
 var versionStr string
+
+const defaultMockBind = "127.0.0.1:8087"
+
 var (
 	engine  snowboard.Parser
 	engineC snowboard.Parser
@@ -44,57 +56,207 @@ func main() {
 	app.Usage = "API blueprint toolkit"
 	app.Version = versionStr
 	app.Commands = []cli.Command{
+		{
+			Name:      "init",
+			Usage:     "Create project configuration and starter API blueprint",
+			ArgsUsage: "[dir]",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "force",
+					Usage: "Overwrite existing files",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return initProject(c, c.Args().First(), c.Bool("force"))
+			},
+		},
 		{
 			Name:  "lint",
 			Usage: "Validate API blueprint",
 			Flags: []cli.Flag{
 				cli.StringFlag{
-					Name:  "i",
-					Usage: "API blueprint file",
+					Name:  "i, input",
+					Usage: "API blueprint file, or - for standard input",
 				},
 				cli.BoolFlag{
 					Name:  "u",
 					Usage: "Use line and row number instead of charater index",
 				},
+				cli.StringSliceFlag{
+					Name:  "r, rule",
+					Usage: "Enable lint rule, as name or name=severity where severity is error, warning or off",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				rules, err := snowboard.ParseLintRules(c.StringSlice("r"))
+				if err != nil {
+					return err
+				}
+
+				if err := validate(c, c.String("i"), c.Bool("u")); err != nil {
+					return err
+				}
+
+				return lint(c, c.String("i"), rules)
+			},
+		},
+		{
+			Name:  "lsp",
+			Usage: "Run language server over standard input and output",
+			Flags: []cli.Flag{
+				cli.StringSliceFlag{
+					Name:  "r, rule",
+					Usage: "Enable lint rule, as name or name=severity where severity is error, warning or off",
+				},
 			},
 			Action: func(c *cli.Context) error {
-				return validate(c, c.String("i"), c.Bool("u"))
+				rules, err := snowboard.ParseLintRules(c.StringSlice("r"))
+				if err != nil {
+					return err
+				}
+
+				return lsp.NewServer(engine, rules).Serve(os.Stdin, os.Stdout)
 			},
 		},
 		{
 			Name:  "html",
 			Usage: "Render HTML documentation",
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
+				cli.StringSliceFlag{
+					Name:  "i, input",
+					Usage: "API blueprint file, or - for standard input, repeat to combine several services",
+				},
 				cli.StringFlag{
-					Name:  "i",
-					Usage: "API blueprint file",
+					Name:  "m, manifest",
+					Usage: "Manifest of combined services",
 				},
 				cli.StringFlag{
-					Name:  "o",
+					Name:  "o, output",
 					Value: "index.html",
-					Usage: "HTML file",
+					Usage: "HTML file, - for standard output, or landing page of combined services",
 				},
 				cli.StringFlag{
-					Name:  "t",
+					Name:  "t, template",
 					Value: "alpha",
 					Usage: "Template for HTML documentation",
 				},
 				cli.BoolFlag{
-					Name:  "s",
+					Name:  "s, serve",
 					Usage: "Serve HTML via HTTP server",
 				},
 				cli.StringFlag{
-					Name:  "b",
+					Name:  "b, bind",
 					Value: "127.0.0.1:8088",
 					Usage: "HTTP server listen address",
 				},
-			},
+				cli.BoolFlag{
+					Name:  "inline",
+					Usage: "Embed stylesheets, scripts and fonts into single HTML file",
+				},
+				cli.BoolFlag{
+					Name:  "console",
+					Usage: "Add interactive console for sending requests",
+				},
+				cli.StringFlag{
+					Name:  "console-url",
+					Usage: "Base URL of console requests, defaults to documented HOST or mock server",
+				},
+			}, tlsFlags...),
 			Action: func(c *cli.Context) error {
+				o := snowboard.HTMLOptions{
+					Console: c.Bool("console"),
+					BaseURL: c.String("console-url"),
+				}
+
+				m, err := manifest(c)
+				if err != nil {
+					return err
+				}
+
+				input, output, tplFile, inline := firstInput(c), c.String("o"), c.String("t"), c.Bool("inline")
+
+				render := func() error {
+					return renderHTML(c, input, output, tplFile, inline, o)
+				}
+
+				inputs := []string{input}
+				pages := map[string]string{}
+
+				if m != nil {
+					render = func() error {
+						return renderSite(c, m, output, tplFile, inline, o)
+					}
+
+					inputs = []string{}
+
+					for _, s := range m.Services {
+						inputs = append(inputs, s.Input)
+						pages["/"+s.Name+".html"] = filepath.Join(filepath.Dir(output), s.Name+".html")
+					}
+				}
+
+				if m != nil && output == stdio {
+					return errors.New("Unable to write documentation of combined services into standard output")
+				}
+
 				if c.Bool("s") {
-					return watchHTML(c, c.String("i"), c.String("o"), c.String("t"), c.String("b"))
+					if input == stdio || output == stdio {
+						return errors.New("Unable to serve documentation from standard input or output")
+					}
+
+					so, err := newServerOptions(c)
+					if err != nil {
+						return err
+					}
+
+					return watchHTML(c, inputs, output, tplFile, c.String("b"), pages, render, so)
 				}
 
-				return renderHTML(c, c.String("i"), c.String("o"), c.String("t"))
+				return render()
+			},
+		},
+		{
+			Name:  "markdown",
+			Usage: "Render Markdown documentation",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "i, input",
+					Usage: "API blueprint file, or - for standard input",
+				},
+				cli.StringFlag{
+					Name:  "o, output",
+					Value: "index.md",
+					Usage: "Markdown file, - for standard output, or directory when splitted",
+				},
+				cli.BoolFlag{
+					Name:  "split",
+					Usage: "Split documentation into one file per resource group",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return renderText(c, c.String("i"), c.String("o"), "markdown", c.Bool("split"))
+			},
+		},
+		{
+			Name:  "asciidoc",
+			Usage: "Render AsciiDoc documentation",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "i, input",
+					Usage: "API blueprint file, or - for standard input",
+				},
+				cli.StringFlag{
+					Name:  "o, output",
+					Value: "index.adoc",
+					Usage: "AsciiDoc file, - for standard output, or directory when splitted",
+				},
+				cli.BoolFlag{
+					Name:  "split",
+					Usage: "Split documentation into one file per resource group",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return renderText(c, c.String("i"), c.String("o"), "asciidoc", c.Bool("split"))
 			},
 		},
 		{
@@ -102,34 +264,311 @@ func main() {
 			Usage: "Render API blueprint",
 			Flags: []cli.Flag{
 				cli.StringFlag{
-					Name:  "i",
-					Usage: "API blueprint file",
+					Name:  "i, input",
+					Usage: "API blueprint file, or - for standard input",
 				},
 				cli.StringFlag{
-					Name:  "o",
-					Usage: "API blueprint output file",
+					Name:  "o, output",
+					Usage: "API blueprint output file, or - for standard output",
 				},
 			},
 			Action: func(c *cli.Context) error {
 				return renderAPIB(c, c.String("i"), c.String("o"))
 			},
 		},
+		{
+			Name:      "show",
+			Usage:     "Browse API documentation in terminal",
+			ArgsUsage: "[pattern]",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "i, input",
+					Usage: "API blueprint file, or - for standard input",
+				},
+				cli.StringFlag{
+					Name:  "m, method",
+					Usage: "Filter by HTTP method",
+				},
+				cli.StringFlag{
+					Name:  "g, group",
+					Usage: "Filter by resource group",
+				},
+				cli.BoolFlag{
+					Name:  "plain",
+					Usage: "Disable colors and paging",
+				},
+				cli.BoolFlag{
+					Name:  "no-pager",
+					Usage: "Disable paging",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				f := snowboard.EndpointFilter{
+					Pattern: c.Args().First(),
+					Method:  c.String("m"),
+					Group:   c.String("g"),
+				}
+
+				return showDocs(c, c.String("i"), f, c.Bool("plain"), c.Bool("no-pager"))
+			},
+		},
+		{
+			Name:  "gen",
+			Usage: "Generate code from API blueprint",
+			Subcommands: []cli.Command{
+				{
+					Name:  "go-client",
+					Usage: "Generate Go client package",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "i, input",
+							Usage: "API blueprint file, or - for standard input",
+						},
+						cli.StringFlag{
+							Name:  "o, output",
+							Value: "client",
+							Usage: "Output directory, or - for standard output",
+						},
+						cli.StringFlag{
+							Name:  "package",
+							Usage: "Package name, defaults to output directory name",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						return generate(c, c.String("i"), c.String("o"), c.String("package"), "go-client")
+					},
+				},
+				{
+					Name:  "go-server",
+					Usage: "Generate Go server package",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "i, input",
+							Usage: "API blueprint file, or - for standard input",
+						},
+						cli.StringFlag{
+							Name:  "o, output",
+							Value: "server",
+							Usage: "Output directory",
+						},
+						cli.StringFlag{
+							Name:  "package",
+							Usage: "Package name, defaults to output directory name",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						return generate(c, c.String("i"), c.String("o"), c.String("package"), "go-server")
+					},
+				},
+				{
+					Name:  "typescript",
+					Usage: "Generate TypeScript types and fetch-based client",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "i, input",
+							Usage: "API blueprint file, or - for standard input",
+						},
+						cli.StringFlag{
+							Name:  "o, output",
+							Value: "client",
+							Usage: "Output directory, or - for standard output",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						return generate(c, c.String("i"), c.String("o"), "", "typescript")
+					},
+				},
+			},
+		},
 		{
 			Name:  "mock",
 			Usage: "Run Mock server",
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
+				cli.StringSliceFlag{
+					Name:  "i, input",
+					Usage: "API blueprint file, or - for standard input, repeat to combine several services",
+				},
 				cli.StringFlag{
-					Name:  "i",
-					Usage: "API blueprint file",
+					Name:  "m, manifest",
+					Usage: "Manifest of combined services",
 				},
 				cli.StringFlag{
-					Name:  "b",
-					Value: "127.0.0.1:8087",
+					Name:  "b, bind",
+					Value: defaultMockBind,
 					Usage: "HTTP server listen address",
 				},
+				cli.StringFlag{
+					Name:  "c, config",
+					Usage: "Mock configuration file",
+				},
+				cli.StringFlag{
+					Name:  "latency",
+					Usage: "Latency of every response, fixed (200ms) or random within range (100ms-2s)",
+				},
+				cli.Float64Flag{
+					Name:  "failure-rate",
+					Usage: "Probability of responding with documented 5xx response",
+				},
+				cli.Float64Flag{
+					Name:  "drop-rate",
+					Usage: "Probability of dropping connection without response",
+				},
+				cli.Float64Flag{
+					Name:  "truncate-rate",
+					Usage: "Probability of truncating response body",
+				},
+				cli.IntFlag{
+					Name:  "rate-limit",
+					Usage: "Maximum number of requests of each route within rate window, exceeding requests get 429",
+				},
+				cli.StringFlag{
+					Name:  "rate-window",
+					Usage: "Rate limit window, defaults to 1s",
+				},
+				cli.BoolFlag{
+					Name:  "cors",
+					Usage: "Enable CORS, allowing any origin unless --cors-origin is given",
+				},
+				cli.StringSliceFlag{
+					Name:  "cors-origin",
+					Usage: "Allowed CORS origin, may contain * wildcard (implies --cors)",
+				},
+				cli.StringSliceFlag{
+					Name:  "cors-method",
+					Usage: "Allowed CORS method, defaults to methods of requested route (implies --cors)",
+				},
+				cli.StringSliceFlag{
+					Name:  "cors-header",
+					Usage: "Allowed CORS request header, defaults to headers requested by preflight (implies --cors)",
+				},
+				cli.IntFlag{
+					Name:  "history",
+					Value: 100,
+					Usage: "Number of recent requests kept for admin API",
+				},
+				cli.StringFlag{
+					Name:  "journal",
+					Usage: "Record requests and responses into JSON lines file",
+				},
+				cli.IntFlag{
+					Name:  "journal-size",
+					Value: 10,
+					Usage: "Size in megabytes after which journal is rotated",
+				},
+				cli.IntFlag{
+					Name:  "journal-backups",
+					Value: 3,
+					Usage: "Number of rotated journal files kept",
+				},
+			}, tlsFlags...),
+			Action: func(c *cli.Context) error {
+				x := snowboard.MockChaos{
+					Latency:      c.String("latency"),
+					FailureRate:  c.Float64("failure-rate"),
+					DropRate:     c.Float64("drop-rate"),
+					TruncateRate: c.Float64("truncate-rate"),
+					RateLimit:    c.Int("rate-limit"),
+					RateWindow:   c.String("rate-window"),
+				}
+
+				var cors *snowboard.MockCORS
+
+				if c.Bool("cors") || len(c.StringSlice("cors-origin")) > 0 || len(c.StringSlice("cors-method")) > 0 || len(c.StringSlice("cors-header")) > 0 {
+					cors = &snowboard.MockCORS{
+						Origins: c.StringSlice("cors-origin"),
+						Methods: c.StringSlice("cors-method"),
+						Headers: c.StringSlice("cors-header"),
+					}
+				}
+
+				so, err := newServerOptions(c)
+				if err != nil {
+					return err
+				}
+
+				var j *snowboard.MockJournal
+
+				if name := c.String("journal"); name != "" {
+					j, err = snowboard.OpenMockJournal(name, int64(c.Int("journal-size"))<<20, c.Int("journal-backups"))
+					if err != nil {
+						return err
+					}
+					defer j.Close()
+				}
+
+				services := []snowboard.Service{{Input: firstInput(c)}}
+
+				m, err := manifest(c)
+				if err != nil {
+					return err
+				}
+
+				if m != nil {
+					services = m.Services
+				}
+
+				return serveMock(c, c.String("b"), services, c.String("c"), x, cors, j, so)
+			},
+		},
+		{
+			Name:      "har",
+			Usage:     "Export mock journal as HTTP Archive",
+			ArgsUsage: "[journal files...]",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "o, output",
+					Value: "mock.har",
+					Usage: "HAR file, or - for standard output",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return exportHAR(c, c.Args(), c.String("o"))
+			},
+		},
+		{
+			Name:      "coverage",
+			Usage:     "Report documented responses exercised by mock journal or HAR files",
+			ArgsUsage: "[traffic files...]",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "i, input",
+					Usage: "API blueprint file, or - for standard input",
+				},
+				cli.StringFlag{
+					Name:  "f, format",
+					Value: "text",
+					Usage: "Output format: text, json or html",
+				},
+				cli.StringFlag{
+					Name:  "o, output",
+					Usage: "Output file, defaults to standard output",
+				},
+				cli.Float64Flag{
+					Name:  "threshold",
+					Usage: "Fail when coverage percentage is below threshold",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return reportCoverage(c, c.String("i"), c.Args(), c.String("f"), c.String("o"), c.Float64("threshold"))
+			},
+		},
+		{
+			Name:      "verify-traffic",
+			Usage:     "Verify recorded HAR or mock journal traffic against API blueprint",
+			ArgsUsage: "[traffic files...]",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "i, input",
+					Usage: "API blueprint file, or - for standard input",
+				},
+				cli.StringFlag{
+					Name:  "f, format",
+					Value: "text",
+					Usage: "Output format: text or json",
+				},
 			},
 			Action: func(c *cli.Context) error {
-				return serveMock(c, c.String("b"), c.String("i"))
+				return verifyTraffic(c, c.String("i"), c.Args(), c.String("f"))
 			},
 		},
 		{
@@ -155,9 +594,90 @@ func main() {
 			},
 		},
 	}
+	configure(app)
 	app.Run(os.Args)
 }
 
+// seedFile returns absolute path of --seed flag
+func seedFile(c *cli.Context) string {
+	seed := c.GlobalString("seed")
+	if seed == "" {
+		return ""
+	}
+
+	if abs, err := filepath.Abs(seed); err == nil {
+		return abs
+	}
+
+	return seed
+}
+
+// source returns API blueprint of input along with --seed and --base-dir
+// flags. Input "-" is read from standard input.
+func source(c *cli.Context, input string) (snowboard.Source, error) {
+	src := snowboard.Source{Name: input, Seed: seedFile(c), BaseDir: c.GlobalString("base-dir")}
+
+	if input == stdio {
+		b, err := readInput(input)
+		if err != nil {
+			return src, err
+		}
+
+		src.Reader = bytes.NewReader(b)
+	}
+
+	return src, nil
+}
+
+func load(c *cli.Context, input string) (*api.API, error) {
+	src, err := source(c, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return snowboard.LoadSource(src, engine)
+}
+
+// stdio is the name of standard input and output in -i and -o flags
+const stdio = "-"
+
+var stdin struct {
+	once sync.Once
+	b    []byte
+	err  error
+}
+
+// readInput reads file, or standard input when name is "-". Standard input is
+// read once and kept, so mock server reloads see the same blueprint.
+func readInput(name string) ([]byte, error) {
+	if name != stdio {
+		return readFile(name)
+	}
+
+	stdin.once.Do(func() {
+		stdin.b, stdin.err = ioutil.ReadAll(os.Stdin)
+	})
+
+	return stdin.b, stdin.err
+}
+
+// writeOutput writes b into file, or standard output when name is "-"
+func writeOutput(c *cli.Context, name string, b []byte) error {
+	if name == stdio {
+		_, err := c.App.Writer.Write(b)
+		return err
+	}
+
+	return ioutil.WriteFile(name, b, 0644)
+}
+
+// generated prints message unless output went to standard output
+func generated(c *cli.Context, output, message string) {
+	if output != stdio {
+		fmt.Fprintln(c.App.Writer, message)
+	}
+}
+
 func readFile(fn string) ([]byte, error) {
 	info, err := os.Stat(fn)
 	if err != nil {
@@ -187,105 +707,272 @@ func readTemplate(fn string) ([]byte, error) {
 	return ioutil.ReadAll(ff)
 }
 
-func renderHTML(c *cli.Context, input, output, tplFile string) error {
-	bp, err := snowboard.Load(input, engine)
-	if err != nil {
-		return err
+// assetOpener opens theme assets. Relative assets are read next to the
+// template file, the rest are read from embedded templates/assets directory.
+func assetOpener(tplFile string) snowboard.AssetOpener {
+	return func(name string) ([]byte, error) {
+		if _, err := os.Stat(tplFile); err == nil {
+			b, err := readFile(filepath.Join(filepath.Dir(tplFile), name))
+			if err == nil {
+				return b, nil
+			}
+		}
+
+		fs := FS(false)
+		ff, err := fs.Open("/templates/assets/" + name)
+		if err != nil {
+			return nil, err
+		}
+
+		defer ff.Close()
+		return ioutil.ReadAll(ff)
 	}
+}
 
-	of, err := os.Create(output)
-	if err != nil {
-		return err
+// manifest returns services of -m manifest or several -i inputs, or nil when
+// a single blueprint is given
+func manifest(c *cli.Context) (*snowboard.Manifest, error) {
+	if name := c.String("m"); name != "" {
+		return snowboard.LoadManifest(name)
 	}
-	defer of.Close()
 
-	tf, err := readTemplate(tplFile)
-	if err != nil {
-		return err
+	if inputs := c.StringSlice("i"); len(inputs) > 1 {
+		return snowboard.NewManifest(inputs)
 	}
 
-	err = snowboard.HTML(string(tf), of, bp)
-	if err != nil {
-		return err
+	return nil, nil
+}
+
+func firstInput(c *cli.Context) string {
+	if inputs := c.StringSlice("i"); len(inputs) > 0 {
+		return inputs[0]
 	}
 
-	fmt.Fprintln(c.App.Writer, "HTML has been generated!")
-	return nil
+	return ""
 }
 
-func renderAPIB(c *cli.Context, input, output string) error {
-	b, err := snowboard.Read(input)
+func renderHTML(c *cli.Context, input, output, tplFile string, inline bool, o snowboard.HTMLOptions) error {
+	bp, err := load(c, input)
 	if err != nil {
 		return err
 	}
 
-	of, err := os.Create(output)
-	if err != nil {
-		return err
+	if o.Console && o.BaseURL == "" && bp.Host() == "" {
+		o.BaseURL = "http://" + defaultMockBind
 	}
-	defer of.Close()
 
-	_, err = io.Copy(of, bytes.NewReader(b))
+	err = writeHTML(c, bp, output, tplFile, inline, o)
 	if err != nil {
 		return err
 	}
 
-	fmt.Fprintln(c.App.Writer, "API blueprint has been generated!")
+	generated(c, output, "HTML has been generated!")
 	return nil
 }
 
-func validate(c *cli.Context, input string, lineNum bool) error {
-	b, err := readFile(input)
-	if err != nil {
-		return err
-	}
+// renderSite renders documentation of every service next to output, which
+// becomes the landing page linking them. Console requests of services go to
+// their mock prefix unless they document HOST.
+func renderSite(c *cli.Context, m *snowboard.Manifest, output, tplFile string, inline bool, o snowboard.HTMLOptions) error {
+	page := snowboard.LandingPage{Title: m.Title}
 
-	bf := bytes.NewReader(b)
-
-	if !lineNum {
-		out, err := snowboard.Validate(bf, engine)
+	for _, s := range m.Services {
+		bp, err := load(c, s.Input)
 		if err != nil {
 			return err
 		}
 
-		if out == nil {
-			fmt.Fprintln(c.App.Writer, "OK")
-			return nil
-		}
-
-		var buf bytes.Buffer
-
-		s := "--------"
-		w := tabwriter.NewWriter(&buf, 8, 0, 0, ' ', tabwriter.Debug)
-		fmt.Fprintln(w, "Row\tCol\tDescription")
-		fmt.Fprintf(w, "%s\t%s\t%s\n", s, s, strings.Repeat(s, 8))
+		so := o
 
-		for _, n := range out.Annotations {
-			for _, m := range n.SourceMaps {
-				fmt.Fprintf(w, "%d\t%d\t%s\n", m.Row, m.Col, n.Description)
-			}
+		switch {
+		case o.BaseURL != "":
+			so.BaseURL = strings.TrimSuffix(o.BaseURL, "/") + s.Prefix
+		case o.Console && bp.Host() == "":
+			so.BaseURL = "http://" + defaultMockBind + s.Prefix
 		}
 
-		w.Flush()
+		file := s.Name + ".html"
 
-		if len(out.Annotations) > 0 {
-			return errors.New(buf.String())
-		}
-	} else {
-		b, err := engineC.Validate(bf)
+		err = writeHTML(c, bp, filepath.Join(filepath.Dir(output), file), tplFile, inline, so)
 		if err != nil {
 			return err
 		}
 
-		var buf bytes.Buffer
+		page.Services = append(page.Services, snowboard.LandingService{
+			MountedAPI: snowboard.MountedAPI{Service: s, API: bp},
+			File:       file,
+		})
+	}
 
-		w := tabwriter.NewWriter(&buf, 8, 0, 0, ' ', tabwriter.Debug)
-		fmt.Fprintln(w, "Location\tSeverity\tDescription")
+	var buf bytes.Buffer
+
+	err := snowboard.Landing(&buf, page)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(output, buf.Bytes(), 0644)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(c.App.Writer, "HTML has been generated!")
+	return nil
+}
+
+func writeHTML(c *cli.Context, bp *api.API, output, tplFile string, inline bool, o snowboard.HTMLOptions) error {
+	tf, err := readTemplate(tplFile)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+
+	err = snowboard.HTMLWithOptions(string(tf), &buf, bp, o)
+	if err != nil {
+		return err
+	}
+
+	b := buf.Bytes()
+
+	if inline {
+		b, err = snowboard.Inline(b, assetOpener(tplFile))
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeOutput(c, output, b)
+}
+
+func renderText(c *cli.Context, input, output, format string, split bool) error {
+	if split && output == stdio {
+		return errors.New("Unable to split documentation into standard output")
+	}
+
+	bp, err := load(c, input)
+	if err != nil {
+		return err
+	}
+
+	if split {
+		var ds []snowboard.Document
+
+		switch format {
+		case "asciidoc":
+			ds = snowboard.AsciiDocSplit(bp)
+		default:
+			ds = snowboard.MarkdownSplit(bp)
+		}
+
+		err = os.MkdirAll(output, 0755)
+		if err != nil {
+			return err
+		}
+
+		for _, d := range ds {
+			err = ioutil.WriteFile(filepath.Join(output, d.Name), d.Body, 0644)
+			if err != nil {
+				return err
+			}
+		}
+
+		fmt.Fprintf(c.App.Writer, "%d files have been generated!\n", len(ds))
+		return nil
+	}
+
+	var buf bytes.Buffer
+
+	switch format {
+	case "asciidoc":
+		err = snowboard.AsciiDoc(&buf, bp)
+	default:
+		err = snowboard.Markdown(&buf, bp)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	err = writeOutput(c, output, buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	generated(c, output, "Documentation has been generated!")
+	return nil
+}
+
+func renderAPIB(c *cli.Context, input, output string) error {
+	src, err := source(c, input)
+	if err != nil {
+		return err
+	}
+
+	b, err := snowboard.ReadSource(src)
+	if err != nil {
+		return err
+	}
+
+	err = writeOutput(c, output, b)
+	if err != nil {
+		return err
+	}
+
+	generated(c, output, "API blueprint has been generated!")
+	return nil
+}
+
+func validate(c *cli.Context, input string, lineNum bool) error {
+	b, err := readInput(input)
+	if err != nil {
+		return err
+	}
+
+	bf := bytes.NewReader(b)
+
+	if !lineNum {
+		out, err := snowboard.Validate(bf, engine)
+		if err != nil {
+			return err
+		}
+
+		if out == nil {
+			return nil
+		}
+
+		var buf bytes.Buffer
+
+		s := "--------"
+		w := tabwriter.NewWriter(&buf, 8, 0, 0, ' ', tabwriter.Debug)
+		fmt.Fprintln(w, "Row\tCol\tDescription")
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s, s, strings.Repeat(s, 8))
+
+		for _, n := range out.Annotations {
+			for _, m := range n.SourceMaps {
+				fmt.Fprintf(w, "%d\t%d\t%s\n", m.Row, m.Col, n.Description)
+			}
+		}
+
+		w.Flush()
+
+		if len(out.Annotations) > 0 {
+			return errors.New(buf.String())
+		}
+	} else {
+		b, err := engineC.Validate(bf)
+		if err != nil {
+			return err
+		}
+
+		var buf bytes.Buffer
+
+		w := tabwriter.NewWriter(&buf, 8, 0, 0, ' ', tabwriter.Debug)
+		fmt.Fprintln(w, "Location\tSeverity\tDescription")
 
 		fmt.Fprintf(w, "%s\t%s\t%s\n", dash(42), dash(16), dash(80))
 
 		if string(b) == "" {
-			fmt.Fprintln(c.App.Writer, "OK")
 			return nil
 		}
 
@@ -304,24 +991,70 @@ func validate(c *cli.Context, input string, lineNum bool) error {
 	return nil
 }
 
+// lint checks blueprint against enabled lint rules. Warnings are printed,
+// errors fail.
+func lint(c *cli.Context, input string, rules map[string]string) error {
+	if len(rules) > 0 {
+		bp, err := load(c, input)
+		if err != nil {
+			return err
+		}
+
+		if ps := snowboard.Lint(bp, rules); len(ps) > 0 {
+			return lintProblems(c, ps)
+		}
+	}
+
+	fmt.Fprintln(c.App.Writer, "OK")
+	return nil
+}
+
+func lintProblems(c *cli.Context, ps []snowboard.LintProblem) error {
+	var buf bytes.Buffer
+
+	w := tabwriter.NewWriter(&buf, 8, 0, 0, ' ', tabwriter.Debug)
+	fmt.Fprintln(w, "Severity\tRule\tDescription")
+	fmt.Fprintf(w, "%s\t%s\t%s\n", dash(8), dash(24), dash(80))
+
+	n := 0
+
+	for _, p := range ps {
+		if p.Severity == snowboard.LintError {
+			n++
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\n", p.Severity, p.Rule, p.Message)
+	}
+
+	w.Flush()
+
+	if n > 0 {
+		return errors.New(buf.String())
+	}
+
+	fmt.Fprint(c.App.Writer, buf.String())
+	return nil
+}
+
 func dash(n int) string {
 	return strings.Repeat("-", n)
 }
 
-func watchHTML(c *cli.Context, input, output, tplFile, bind string) error {
+func watchHTML(c *cli.Context, inputs []string, output, tplFile, bind string, pages map[string]string, render func() error, so serverOptions) error {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return err
 	}
 	defer watcher.Close()
 
-	done := make(chan bool)
 	go func() {
 		for {
 			select {
 			case event := <-watcher.Events:
 				if event.Op&fsnotify.Write == fsnotify.Write {
-					renderHTML(c, input, output, tplFile)
+					if err := render(); err != nil {
+						fmt.Fprintln(c.App.Writer, err)
+					}
 				}
 			case err := <-watcher.Errors:
 				fmt.Fprintln(c.App.Writer, err)
@@ -329,9 +1062,11 @@ func watchHTML(c *cli.Context, input, output, tplFile, bind string) error {
 		}
 	}()
 
-	err = watcher.Add(input)
-	if err != nil {
-		return err
+	for _, input := range inputs {
+		err = watcher.Add(input)
+		if err != nil {
+			return err
+		}
 	}
 
 	if _, err = os.Stat(tplFile); err == nil {
@@ -341,38 +1076,514 @@ func watchHTML(c *cli.Context, input, output, tplFile, bind string) error {
 		}
 	}
 
-	renderHTML(c, input, output, tplFile)
-	serveHTML(bind, output)
-
-	<-done
+	if err := render(); err != nil {
+		fmt.Fprintln(c.App.Writer, err)
+	}
 
-	return nil
+	return serveHTML(c, bind, output, pages, so)
 }
 
-func serveHTML(bind, output string) error {
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+// serveHTML serves output on every path, except pages of combined services
+func serveHTML(c *cli.Context, bind, output string, pages map[string]string, so serverOptions) error {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p, ok := pages[r.URL.Path]; ok {
+			http.ServeFile(w, r, p)
+			return
+		}
+
 		http.ServeFile(w, r, output)
 	})
 
-	return http.ListenAndServe(bind, nil)
+	fmt.Fprintf(c.App.Writer, "Serving %s on %s\n", output, so.url(bind))
+
+	return listenAndServe(c, bind, h, so)
+}
+
+func showDocs(c *cli.Context, input string, f snowboard.EndpointFilter, plain, noPager bool) error {
+	bp, err := load(c, input)
+	if err != nil {
+		return err
+	}
+
+	es := f.Filter(snowboard.Endpoints(bp))
+	if len(es) == 0 {
+		return errors.New("No endpoint matches")
+	}
+
+	for _, e := range es {
+		if f.Pattern != "" && e.Transition.Permalink == f.Pattern {
+			es = []snowboard.Endpoint{e}
+		}
+	}
+
+	return page(c, plain, noPager, func(w io.Writer, color bool) error {
+		if len(es) == 1 {
+			return snowboard.TerminalDetail(w, es[0], color)
+		}
+
+		return snowboard.TerminalList(w, es, color)
+	})
+}
+
+// page writes output through $PAGER when standard output is a terminal
+func page(c *cli.Context, plain, noPager bool, fn func(w io.Writer, color bool) error) error {
+	if plain || !isTerminal(os.Stdout) {
+		return fn(c.App.Writer, false)
+	}
+
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less -FRX"
+	}
+
+	args := strings.Fields(pager)
+	if noPager || len(args) == 0 {
+		return fn(c.App.Writer, true)
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	w, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	if err = cmd.Start(); err != nil {
+		return fn(c.App.Writer, true)
+	}
+
+	err = fn(w, true)
+	w.Close()
+
+	if werr := cmd.Wait(); err == nil {
+		err = werr
+	}
+
+	return err
+}
+
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+func generate(c *cli.Context, input, output, pkg, kind string) error {
+	if kind == "go-server" && output == stdio {
+		return errors.New("Unable to write Go server package, which has several files, into standard output")
+	}
+
+	bp, err := load(c, input)
+	if err != nil {
+		return err
+	}
+
+	if pkg == "" && output == stdio {
+		pkg = "client"
+	} else if pkg == "" {
+		pkg = packageName(output)
+	}
+
+	files := map[string]func(*api.API, string) ([]byte, error){}
+	stubs := map[string]func(*api.API, string) ([]byte, error){}
+
+	switch kind {
+	case "go-client":
+		files["client.go"] = generator.GoClient
+	case "go-server":
+		files["server_gen.go"] = generator.GoServer
+		stubs["service.go"] = generator.GoServerStub
+	case "typescript":
+		files["client.ts"] = func(b *api.API, _ string) ([]byte, error) {
+			return generator.TypeScript(b)
+		}
+	}
+
+	// single file of client is written as is
+	if output == stdio {
+		for _, fn := range files {
+			b, err := fn(bp, pkg)
+			if err != nil {
+				return err
+			}
+
+			return writeOutput(c, output, b)
+		}
+	}
+
+	err = os.MkdirAll(output, 0755)
+	if err != nil {
+		return err
+	}
+
+	// stubs are edited by hand, so they are never overwritten
+	for name, fn := range stubs {
+		if _, err := os.Stat(filepath.Join(output, name)); err == nil {
+			continue
+		}
+
+		files[name] = fn
+	}
+
+	for name, fn := range files {
+		b, err := fn(bp, pkg)
+		if err != nil {
+			return err
+		}
+
+		err = ioutil.WriteFile(filepath.Join(output, name), b, 0644)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(c.App.Writer, "%s has been generated!\n", filepath.Join(output, name))
+	}
+
+	return nil
 }
 
-func serveMock(c *cli.Context, bind, input string) error {
-	bp, err := snowboard.Load(input, engine)
+func packageName(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+
+	s := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+
+		return -1
+	}, strings.ToLower(filepath.Base(abs)))
+
+	if s == "" || s[0] >= '0' && s[0] <= '9' {
+		s = "api" + s
+	}
+
+	return s
+}
+
+func serveMock(c *cli.Context, bind string, services []snowboard.Service, config string, x snowboard.MockChaos, cors *snowboard.MockCORS, j *snowboard.MockJournal, so serverOptions) error {
+	load := func() (snowboard.MockTransactions, *snowboard.MockConfig, error) {
+		xs := []snowboard.MountedAPI{}
+
+		for _, s := range services {
+			bp, err := load(c, s.Input)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			xs = append(xs, snowboard.MountedAPI{Service: s, API: bp})
+		}
+
+		ms, err := snowboard.MockServices(xs)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		mc := &snowboard.MockConfig{}
+
+		if config != "" {
+			mc, err = snowboard.LoadMockConfig(config)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		// flags apply to every route and take precedence over config file
+		mc.Chaos = append(mc.Chaos, x)
+
+		if cors != nil {
+			mc.CORS = cors
+		}
+
+		return ms, mc, nil
+	}
+
+	s, err := snowboard.LoadMockServer(load)
+	if err != nil {
+		return err
+	}
+
+	s.SetLogSize(c.Int("history"))
+
+	if j != nil {
+		s.SetJournal(j)
+	}
+
+	inputs := []string{}
+	for _, x := range services {
+		inputs = append(inputs, x.Input)
+	}
+
+	watcher, err := watchMock(c, s, inputs, config)
 	if err != nil {
 		return err
 	}
+	defer watcher.Close()
 
-	fmt.Fprintf(c.App.Writer, "Mock server is ready. Use %s\n", bind)
+	fmt.Fprintf(c.App.Writer, "Mock server is ready. Use %s\n", so.url(bind))
 	fmt.Fprintln(c.App.Writer, "Available Routes:")
 
-	ms := snowboard.Mock(bp)
-	for _, m := range ms {
+	for _, m := range s.Transactions() {
 		fmt.Fprintf(c.App.Writer, "%s\t%d\t%s\n", m.Method, m.StatusCode, m.Pattern)
 	}
 
-	h := snowboard.MockHandler(ms)
-	return http.ListenAndServe(bind, h)
+	return listenAndServe(c, bind, s, so)
+}
+
+func readTraffic(inputs []string) ([]*snowboard.MockLogEntry, error) {
+	if len(inputs) == 0 {
+		return nil, errors.New("Traffic file is required")
+	}
+
+	entries := []*snowboard.MockLogEntry{}
+
+	for _, input := range inputs {
+		xs, err := readTrafficFile(input)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, xs...)
+	}
+
+	return entries, nil
+}
+
+// readTrafficFile reads traffic file, or standard input when name is "-"
+func readTrafficFile(name string) ([]*snowboard.MockLogEntry, error) {
+	if name != stdio {
+		return snowboard.ReadTraffic(name)
+	}
+
+	b, err := readInput(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return snowboard.ParseTraffic(b)
+}
+
+func reportCoverage(c *cli.Context, input string, inputs []string, format, output string, threshold float64) error {
+	bp, err := load(c, input)
+	if err != nil {
+		return err
+	}
+
+	entries, err := readTraffic(inputs)
+	if err != nil {
+		return err
+	}
+
+	report := snowboard.Coverage(bp, entries)
+
+	var buf bytes.Buffer
+
+	switch format {
+	case "text":
+		err = snowboard.CoverageText(&buf, report)
+	case "html":
+		err = snowboard.CoverageHTML(&buf, report)
+	case "json":
+		var b []byte
+
+		b, err = json.MarshalIndent(report, "", "  ")
+		buf.Write(b)
+		buf.WriteByte('\n')
+	default:
+		err = fmt.Errorf("Unknown format %s", format)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		output = stdio
+	}
+
+	if err = writeOutput(c, output, buf.Bytes()); err != nil {
+		return err
+	}
+
+	generated(c, output, output+" has been generated!")
+
+	if report.Percent < threshold {
+		return fmt.Errorf("Coverage %.1f%% is below threshold %.1f%%", report.Percent, threshold)
+	}
+
+	return nil
+}
+
+func verifyTraffic(c *cli.Context, input string, inputs []string, format string) error {
+	bp, err := load(c, input)
+	if err != nil {
+		return err
+	}
+
+	entries, err := readTraffic(inputs)
+	if err != nil {
+		return err
+	}
+
+	report := snowboard.VerifyTraffic(bp, entries)
+
+	switch format {
+	case "text":
+		err = snowboard.TrafficText(c.App.Writer, report)
+	case "json":
+		var b []byte
+
+		b, err = json.MarshalIndent(report, "", "  ")
+		fmt.Fprintln(c.App.Writer, string(b))
+	default:
+		err = fmt.Errorf("Unknown format %s", format)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !report.OK() {
+		return errors.New("Traffic does not conform to API blueprint")
+	}
+
+	return nil
+}
+
+// exportHAR writes entries of journal files, including rotated ones, as a
+// single HAR file ordered by time
+func exportHAR(c *cli.Context, inputs []string, output string) error {
+	entries, err := readTraffic(inputs)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+
+	b, err := snowboard.MockHAR(entries)
+	if err != nil {
+		return err
+	}
+
+	err = writeOutput(c, output, b)
+	if err != nil {
+		return err
+	}
+
+	generated(c, output, output+" has been generated!")
+	return nil
+}
+
+// watchMock reloads mock server whenever blueprint, its partials and seed, or
+// mock configuration changes. Parent directories are watched, so files
+// replaced by editors on save are noticed as well.
+func watchMock(c *cli.Context, s *snowboard.MockServer, inputs []string, config string) (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]bool{}
+	dirs := map[string]bool{}
+
+	update := func() error {
+		fs := []string{}
+
+		for _, input := range inputs {
+			if input == stdio {
+				continue
+			}
+
+			xs, err := snowboard.Files(input)
+			if err != nil {
+				return err
+			}
+
+			fs = append(fs, xs...)
+		}
+
+		if seed := seedFile(c); seed != "" {
+			fs = append(fs, seed)
+		}
+
+		if config != "" {
+			abs, err := filepath.Abs(config)
+			if err != nil {
+				return err
+			}
+
+			fs = append(fs, abs)
+		}
+
+		files = map[string]bool{}
+
+		for _, f := range fs {
+			files[f] = true
+
+			if dir := filepath.Dir(f); !dirs[dir] {
+				if err := watcher.Add(dir); err != nil {
+					return err
+				}
+
+				dirs[dir] = true
+			}
+		}
+
+		return nil
+	}
+
+	if err := update(); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	go func() {
+		var reload <-chan time.Time
+
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				// editors often write several events on save
+				if files[filepath.Clean(event.Name)] {
+					reload = time.After(100 * time.Millisecond)
+				}
+			case <-reload:
+				reload = nil
+
+				if err := s.Reload(); err != nil {
+					fmt.Fprintf(c.App.Writer, "Unable to reload, still serving the last good version: %s\n", err)
+				} else {
+					fmt.Fprintln(c.App.Writer, "Mock server has been reloaded")
+				}
+
+				// partials or seed might be added or removed
+				if err := update(); err != nil {
+					fmt.Fprintln(c.App.Writer, err)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				fmt.Fprintln(c.App.Writer, err)
+			}
+		}
+	}()
+
+	return watcher, nil
 }
 
 func installAdapters(c *cli.Context, dir string) error {
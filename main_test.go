@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	snowboard "github.com/subosito/snowboard/parser"
+	"github.com/urfave/cli"
+)
+
+// fakeEngine parses every blueprint into the same API
+type fakeEngine struct{}
+
+func (fakeEngine) Parse(r io.Reader) ([]byte, error) {
+	return []byte(`{"element": "parseResult", "content": [{"element": "category", "meta": {"classes": ["api"], "title": "Notes API"}, "content": [
+		{"element": "category", "meta": {"classes": ["resourceGroup"], "title": "Notes"}, "content": [
+			{"element": "resource", "meta": {"title": "Note"}, "attributes": {"href": "/notes/{id}"}, "content": [
+				{"element": "transition", "meta": {"title": "Delete a Note"}, "content": [
+					{"element": "httpTransaction", "content": [
+						{"element": "httpRequest", "attributes": {"method": "DELETE"}},
+						{"element": "httpResponse", "attributes": {"statusCode": "204"}}
+					]}
+				]}
+			]}
+		]}
+	]}]}`), nil
+}
+
+func (fakeEngine) Validate(r io.Reader) ([]byte, error) {
+	return []byte(`{"element": "parseResult", "content": []}`), nil
+}
+
+func (fakeEngine) Version() string {
+	return "test"
+}
+
+func TestGenerate_stdout(t *testing.T) {
+	defer func(x snowboard.Parser) { engine = x }(engine)
+	engine = fakeEngine{}
+
+	input := "fixtures/extensions/html-comment.apib"
+
+	var buf bytes.Buffer
+
+	app := cli.NewApp()
+	app.Writer = &buf
+	c := cli.NewContext(app, flag.NewFlagSet("gen", flag.ContinueOnError), nil)
+
+	assert.Nil(t, generate(c, input, stdio, "", "go-client"))
+	assert.Contains(t, buf.String(), "package client\n")
+	assert.NotContains(t, buf.String(), "has been generated")
+
+	buf.Reset()
+
+	assert.Nil(t, generate(c, input, stdio, "", "typescript"))
+	assert.Contains(t, buf.String(), "export ")
+
+	_, err := os.Stat(stdio)
+	assert.True(t, os.IsNotExist(err))
+
+	err = generate(c, input, stdio, "", "go-server")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "standard output")
+}
@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type asciidocDialect struct{}
+
+var (
+	markdownHeadingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	markdownFencePattern   = regexp.MustCompile("^(```+|~~~+)\\s*(\\S*)")
+	markdownLinkPattern    = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	markdownStrongPattern  = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+)
+
+func (asciidocDialect) ext() string {
+	return ".adoc"
+}
+
+func (asciidocDialect) title(s string) string {
+	return "= " + s + "\n"
+}
+
+func (asciidocDialect) heading(level int, s, anchor string) string {
+	return fmt.Sprintf("[[%s]]\n%s %s\n", anchor, strings.Repeat("=", level), s)
+}
+
+func (asciidocDialect) link(s, file, anchor string) string {
+	if file == "" {
+		return fmt.Sprintf("<<%s,%s>>", anchor, s)
+	}
+
+	return fmt.Sprintf("xref:%s#%s[%s]", file, anchor, s)
+}
+
+func (asciidocDialect) item(level int, s string) string {
+	return strings.Repeat("*", level+1) + " " + s
+}
+
+func (asciidocDialect) table(header []string, rows [][]string) string {
+	xs := []string{"|===", asciidocRow(header), ""}
+
+	for _, r := range rows {
+		xs = append(xs, asciidocRow(r))
+	}
+
+	xs = append(xs, "|===")
+
+	return strings.Join(xs, "\n") + "\n"
+}
+
+func (asciidocDialect) code(lang, body string) string {
+	attr := "[source]"
+	if lang != "" {
+		attr = "[source," + lang + "]"
+	}
+
+	return fmt.Sprintf("%s\n----\n%s\n----\n", attr, body)
+}
+
+func (asciidocDialect) strong(s string) string {
+	return "*" + s + "*"
+}
+
+func (asciidocDialect) literal(s string) string {
+	return "`+" + s + "+`"
+}
+
+// copy converts common Markdown constructs of descriptions into AsciiDoc
+func (asciidocDialect) copy(s string) string {
+	xs := []string{}
+	fenced := false
+
+	for _, line := range strings.Split(s, "\n") {
+		if ms := markdownFencePattern.FindStringSubmatch(line); ms != nil {
+			if !fenced && ms[2] != "" {
+				xs = append(xs, "[source,"+ms[2]+"]")
+			}
+
+			xs = append(xs, "----")
+			fenced = !fenced
+			continue
+		}
+
+		if fenced {
+			xs = append(xs, line)
+			continue
+		}
+
+		if ms := markdownHeadingPattern.FindStringSubmatch(line); ms != nil {
+			line = strings.Repeat("=", len(ms[1])+1) + " " + ms[2]
+		}
+
+		line = markdownLinkPattern.ReplaceAllString(line, "$2[$1]")
+		line = markdownStrongPattern.ReplaceAllString(line, "*$1*")
+		xs = append(xs, line)
+	}
+
+	return strings.Join(xs, "\n")
+}
+
+func asciidocRow(cs []string) string {
+	xs := make([]string, len(cs))
+
+	for i, c := range cs {
+		c = strings.Replace(c, "|", `\|`, -1)
+		xs[i] = "|" + strings.Join(strings.Fields(c), " ")
+	}
+
+	return strings.Join(xs, " ")
+}
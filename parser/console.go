@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/subosito/snowboard/api"
+)
+
+type consoleResponse struct {
+	ContentType string `json:"contentType"`
+	Body        string `json:"body"`
+	Schema      string `json:"schema"`
+}
+
+type consoleTransition struct {
+	Host      string                     `json:"host"`
+	Method    string                     `json:"method"`
+	URL       string                     `json:"url"`
+	Headers   []api.Header               `json:"headers"`
+	Body      string                     `json:"body"`
+	Responses map[string]consoleResponse `json:"responses"`
+}
+
+// consoleData serializes transition as JSON to be used by interactive console
+func consoleData(host string, t *api.Transition) string {
+	c := consoleTransition{
+		Host:      host,
+		Method:    t.Method,
+		URL:       t.URL,
+		Headers:   []api.Header{},
+		Responses: map[string]consoleResponse{},
+	}
+
+	for i, x := range t.Transactions {
+		if i == 0 {
+			c.Headers = append(c.Headers, x.Request.Headers...)
+			c.Body = x.Request.Body.Body
+		}
+
+		k := strconv.Itoa(x.Response.StatusCode)
+		if _, ok := c.Responses[k]; ok {
+			continue
+		}
+
+		c.Responses[k] = consoleResponse{
+			ContentType: x.Response.Body.ContentType,
+			Body:        x.Response.Body.Body,
+			Schema:      x.Response.Schema.Body,
+		}
+	}
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "{}"
+	}
+
+	return string(b)
+}
@@ -0,0 +1,263 @@
+package parser
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/subosito/snowboard/api"
+)
+
+// CoverageReport tells which documented responses were exercised by recorded
+// traffic. Every distinct status code of an action counts as one response.
+type CoverageReport struct {
+	Title   string          `json:"title"`
+	Total   int             `json:"total"`
+	Covered int             `json:"covered"`
+	Percent float64         `json:"percent"`
+	Groups  []CoverageGroup `json:"groups"`
+	Gaps    []CoverageGap   `json:"gaps"`
+}
+
+// CoverageGroup is coverage of resource group
+type CoverageGroup struct {
+	Title     string             `json:"title"`
+	Total     int                `json:"total"`
+	Covered   int                `json:"covered"`
+	Resources []CoverageResource `json:"resources"`
+}
+
+// CoverageResource is coverage of resource
+type CoverageResource struct {
+	Title   string           `json:"title"`
+	Total   int              `json:"total"`
+	Covered int              `json:"covered"`
+	Actions []CoverageAction `json:"actions"`
+}
+
+// CoverageAction is coverage of transition, Undocumented lists status codes
+// responded but not documented
+type CoverageAction struct {
+	Title        string             `json:"title"`
+	Method       string             `json:"method"`
+	URL          string             `json:"url"`
+	Hits         int                `json:"hits"`
+	Responses    []CoverageResponse `json:"responses"`
+	Undocumented []int              `json:"undocumented,omitempty"`
+}
+
+// CoverageResponse is documented status code of action along with number of
+// requests responded with it
+type CoverageResponse struct {
+	StatusCode int `json:"status"`
+	Hits       int `json:"hits"`
+}
+
+// CoverageGap is a request matching no documented action
+type CoverageGap struct {
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	StatusCode int    `json:"status"`
+	Hits       int    `json:"hits"`
+}
+
+// Coverage matches recorded traffic against actions of blueprint
+func Coverage(b *api.API, entries []*MockLogEntry) *CoverageReport {
+	tr := newTrafficRouter(b)
+
+	hits := map[*api.Transition]map[int]int{}
+	gaps := gapSet{}
+
+	for _, e := range entries {
+		// connections dropped by chaos have no response
+		if e.StatusCode == 0 {
+			continue
+		}
+
+		if a, _, ok := tr.match(e.Method, e.URL); ok {
+			if hits[a.transition] == nil {
+				hits[a.transition] = map[int]int{}
+			}
+
+			hits[a.transition][e.StatusCode]++
+			continue
+		}
+
+		if !controlEntry(e) {
+			gaps.add(e)
+		}
+	}
+
+	c := &CoverageReport{Title: b.Title, Groups: []CoverageGroup{}, Gaps: gaps.list()}
+
+	for _, g := range b.ResourceGroups {
+		cg := CoverageGroup{Title: g.Title, Resources: []CoverageResource{}}
+
+		for _, r := range g.Resources {
+			cr := CoverageResource{Title: r.Title, Actions: []CoverageAction{}}
+
+			for _, t := range r.Transitions {
+				ca := coverageAction(t, hits[t])
+
+				for _, x := range ca.Responses {
+					cr.Total++
+
+					if x.Hits > 0 {
+						cr.Covered++
+					}
+				}
+
+				cr.Actions = append(cr.Actions, ca)
+			}
+
+			cg.Total += cr.Total
+			cg.Covered += cr.Covered
+			cg.Resources = append(cg.Resources, cr)
+		}
+
+		c.Total += cg.Total
+		c.Covered += cg.Covered
+		c.Groups = append(c.Groups, cg)
+	}
+
+	if c.Total > 0 {
+		c.Percent = float64(c.Covered) * 100 / float64(c.Total)
+	}
+
+	return c
+}
+
+func coverageAction(t *api.Transition, hits map[int]int) CoverageAction {
+	ca := CoverageAction{Title: t.Title, Method: t.Method, URL: t.URL, Responses: []CoverageResponse{}}
+	seen := map[int]bool{}
+
+	for _, n := range t.Transactions {
+		code := n.Response.StatusCode
+		if seen[code] {
+			continue
+		}
+
+		seen[code] = true
+		ca.Responses = append(ca.Responses, CoverageResponse{StatusCode: code, Hits: hits[code]})
+	}
+
+	for code, n := range hits {
+		ca.Hits += n
+
+		if !seen[code] {
+			ca.Undocumented = append(ca.Undocumented, code)
+		}
+	}
+
+	sort.Ints(ca.Undocumented)
+
+	return ca
+}
+
+// CoverageText writes coverage report as plain text table
+func CoverageText(w io.Writer, c *CoverageReport) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "Method\tURL\tStatus\tHits")
+
+	for _, g := range c.Groups {
+		for _, r := range g.Resources {
+			for _, a := range r.Actions {
+				for _, x := range a.Responses {
+					mark := ""
+					if x.Hits == 0 {
+						mark = "  (not covered)"
+					}
+
+					fmt.Fprintf(tw, "%s\t%s\t%d\t%d%s\n", a.Method, a.URL, x.StatusCode, x.Hits, mark)
+				}
+
+				for _, code := range a.Undocumented {
+					fmt.Fprintf(tw, "%s\t%s\t%d\t-  (undocumented status)\n", a.Method, a.URL, code)
+				}
+			}
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\nCoverage: %.1f%% (%d of %d responses)\n", c.Percent, c.Covered, c.Total)
+
+	if len(c.Gaps) > 0 {
+		fmt.Fprintln(w, "\nSpec gaps:")
+
+		for _, x := range c.Gaps {
+			fmt.Fprintf(w, "  %s %s (%d, %d requests)\n", x.Method, x.Path, x.StatusCode, x.Hits)
+		}
+	}
+
+	return nil
+}
+
+// CoverageHTML writes coverage report as standalone HTML page
+func CoverageHTML(w io.Writer, c *CoverageReport) error {
+	tpl, err := template.New("coverage").Funcs(template.FuncMap{
+		"percent": func(covered, total int) string {
+			if total == 0 {
+				return "-"
+			}
+
+			return fmt.Sprintf("%.1f%%", float64(covered)*100/float64(total))
+		},
+		"statusText": http.StatusText,
+	}).Parse(coverageTemplate)
+	if err != nil {
+		return err
+	}
+
+	return tpl.Execute(w, c)
+}
+
+const coverageTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ if .Title }}{{ .Title }} - {{ end }}Coverage</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #333; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+th, td { text-align: left; padding: 4px 8px; border-bottom: 1px solid #eee; }
+h2 small, h3 small { color: #888; font-weight: normal; }
+.hit { background: #e6ffed; }
+.miss { background: #ffeef0; }
+.extra { background: #fff5b1; }
+code { font-size: 90%; }
+</style>
+</head>
+<body>
+<h1>{{ if .Title }}{{ .Title }} {{ end }}Coverage <small>{{ percent .Covered .Total }} ({{ .Covered }} of {{ .Total }} responses)</small></h1>
+{{ range .Groups }}
+<h2>{{ if .Title }}{{ .Title }}{{ else }}Resources{{ end }} <small>{{ percent .Covered .Total }}</small></h2>
+{{ range .Resources }}
+<h3>{{ .Title }} <small>{{ percent .Covered .Total }}</small></h3>
+<table>
+<tr><th>Action</th><th>Method</th><th>URL</th><th>Status</th><th>Hits</th></tr>
+{{ range $a := .Actions }}{{ range .Responses }}
+<tr class="{{ if .Hits }}hit{{ else }}miss{{ end }}"><td>{{ $a.Title }}</td><td>{{ $a.Method }}</td><td><code>{{ $a.URL }}</code></td><td>{{ .StatusCode }} {{ statusText .StatusCode }}</td><td>{{ .Hits }}</td></tr>
+{{ end }}{{ range .Undocumented }}
+<tr class="extra"><td>{{ $a.Title }}</td><td>{{ $a.Method }}</td><td><code>{{ $a.URL }}</code></td><td>{{ . }} {{ statusText . }} (undocumented)</td><td></td></tr>
+{{ end }}{{ end }}
+</table>
+{{ end }}
+{{ end }}
+{{ if .Gaps }}
+<h2>Spec gaps</h2>
+<table>
+<tr><th>Method</th><th>Path</th><th>Status</th><th>Hits</th></tr>
+{{ range .Gaps }}<tr class="miss"><td>{{ .Method }}</td><td><code>{{ .Path }}</code></td><td>{{ .StatusCode }}</td><td>{{ .Hits }}</td></tr>
+{{ end }}
+</table>
+{{ end }}
+</body>
+</html>
+`
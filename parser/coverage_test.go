@@ -0,0 +1,131 @@
+package parser_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/subosito/snowboard/api"
+	snowboard "github.com/subosito/snowboard/parser"
+)
+
+func coverageFixture() *api.API {
+	b := renderFixture()
+	t := b.ResourceGroups[0].Resources[0].Transitions[0]
+	t.Transactions = append(t.Transactions, api.Transaction{
+		Request:  api.Request{Method: "GET"},
+		Response: api.Response{StatusCode: 404},
+	})
+
+	return b
+}
+
+func TestCoverage(t *testing.T) {
+	entries := []*snowboard.MockLogEntry{
+		{Method: "GET", URL: "http://127.0.0.1:8087/notes/1", StatusCode: 200},
+		{Method: "GET", URL: "http://127.0.0.1:8087/notes/2", StatusCode: 200},
+		{Method: "GET", URL: "http://127.0.0.1:8087/notes/3", StatusCode: 500},
+		{Method: "GET", URL: "http://127.0.0.1:8087/users", StatusCode: 404},
+		{Method: "GET", URL: "http://127.0.0.1:8087/notes/4"},
+	}
+
+	c := snowboard.Coverage(coverageFixture(), entries)
+	assert.Equal(t, 2, c.Total)
+	assert.Equal(t, 1, c.Covered)
+	assert.Equal(t, float64(50), c.Percent)
+
+	a := c.Groups[0].Resources[0].Actions[0]
+	assert.Equal(t, 3, a.Hits)
+	assert.Equal(t, []snowboard.CoverageResponse{{StatusCode: 200, Hits: 2}, {StatusCode: 404}}, a.Responses)
+	assert.Equal(t, []int{500}, a.Undocumented)
+	assert.Equal(t, []snowboard.CoverageGap{{Method: "GET", Path: "/users", StatusCode: 404, Hits: 1}}, c.Gaps)
+
+	var buf bytes.Buffer
+
+	assert.Nil(t, snowboard.CoverageText(&buf, c))
+	assert.Contains(t, buf.String(), "Coverage: 50.0% (1 of 2 responses)")
+	assert.Contains(t, buf.String(), "GET /users (404, 1 requests)")
+
+	buf.Reset()
+
+	assert.Nil(t, snowboard.CoverageHTML(&buf, c))
+	assert.Contains(t, buf.String(), "<h1>Notes API Coverage <small>50.0%")
+	assert.Contains(t, buf.String(), "404 Not Found")
+}
+
+// queryFixture documents two actions of /notes told apart by their query
+// parameters only
+func queryFixture() *api.API {
+	b := renderFixture()
+	b.ResourceGroups[0].Resources = append(b.ResourceGroups[0].Resources, &api.Resource{
+		Title: "Notes",
+		Transitions: []*api.Transition{
+			{
+				Title:        "List Notes",
+				Method:       "GET",
+				URL:          "/notes{?page}",
+				Transactions: []api.Transaction{{Request: api.Request{Method: "GET"}, Response: api.Response{StatusCode: 200}}},
+			},
+			{
+				Title:        "Search Notes",
+				Method:       "GET",
+				URL:          "/notes{?q}",
+				Href:         api.Href{Parameters: []api.Parameter{{Key: "q", Required: true}}},
+				Transactions: []api.Transaction{{Request: api.Request{Method: "GET"}, Response: api.Response{StatusCode: 200, Body: api.Asset{ContentType: "application/json", Body: `[{"id": 1}]`}}}},
+			},
+		},
+	})
+
+	return b
+}
+
+func TestCoverage_query(t *testing.T) {
+	entries := []*snowboard.MockLogEntry{
+		{Method: "GET", URL: "http://127.0.0.1:8087/notes?page=2", StatusCode: 200},
+		{Method: "GET", URL: "http://127.0.0.1:8087/notes?q=a", StatusCode: 200},
+		{Method: "GET", URL: "http://127.0.0.1:8087/notes?q=b", StatusCode: 200},
+	}
+
+	c := snowboard.Coverage(queryFixture(), entries)
+	as := c.Groups[0].Resources[1].Actions
+	assert.Equal(t, 1, as[0].Hits)
+	assert.Equal(t, 2, as[1].Hits)
+	assert.Empty(t, c.Gaps)
+}
+
+func TestReadHAR(t *testing.T) {
+	entries := []*snowboard.MockLogEntry{
+		{
+			Method:     "POST",
+			URL:        "https://api.example.com/notes?draft=1",
+			StatusCode: 201,
+			Duration:   "2ms",
+			Request:    &snowboard.MockMessage{Headers: map[string][]string{"Content-Type": {"application/json"}}, Body: `{"title": "a"}`},
+			Response:   &snowboard.MockMessage{Headers: map[string][]string{"Content-Type": {"application/json"}}, Body: `{"id": 1}`},
+		},
+	}
+
+	b, err := snowboard.MockHAR(entries)
+	assert.Nil(t, err)
+
+	xs, err := snowboard.ReadHAR(bytes.NewReader(b))
+	assert.Nil(t, err)
+	assert.Len(t, xs, 1)
+	assert.Equal(t, "POST", xs[0].Method)
+	assert.Equal(t, entries[0].URL, xs[0].URL)
+	assert.Equal(t, 201, xs[0].StatusCode)
+	assert.Equal(t, "2ms", xs[0].Duration)
+	assert.Equal(t, `{"title": "a"}`, xs[0].Request.Body)
+	assert.Equal(t, `{"id": 1}`, xs[0].Response.Body)
+	assert.Equal(t, "application/json", xs[0].Response.Headers.Get("Content-Type"))
+
+	_, err = snowboard.ReadHAR(strings.NewReader("{"))
+	assert.NotNil(t, err)
+
+	v, _ := json.Marshal(entries[0])
+	xs, err = snowboard.ReadMockJournal(bytes.NewReader(append(v, '\n')))
+	assert.Nil(t, err)
+	assert.Len(t, xs, 1)
+}
@@ -0,0 +1,28 @@
+package parser
+
+import "strings"
+
+// Format normalizes whitespace of API blueprint. Line endings become LF,
+// trailing whitespace is removed except Markdown hard line breaks, which are
+// kept as two spaces, and document ends with a single newline.
+func Format(b []byte) []byte {
+	s := strings.Replace(string(b), "\r\n", "\n", -1)
+	lines := strings.Split(s, "\n")
+
+	for i, line := range lines {
+		x := strings.TrimRight(line, " \t")
+
+		if x != "" && strings.HasSuffix(line, "  ") {
+			x += "  "
+		}
+
+		lines[i] = x
+	}
+
+	s = strings.TrimRight(strings.Join(lines, "\n"), "\n")
+	if s == "" {
+		return []byte{}
+	}
+
+	return []byte(s + "\n")
+}
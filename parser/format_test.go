@@ -0,0 +1,16 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	snowboard "github.com/subosito/snowboard/parser"
+)
+
+func TestFormat(t *testing.T) {
+	b := snowboard.Format([]byte("# API \r\n\r\nFirst line  \r\nsecond line\t\n   \n+ Response 200\n\n\n"))
+	assert.Equal(t, "# API\n\nFirst line  \nsecond line\n\n+ Response 200\n", string(b))
+
+	assert.Equal(t, "", string(snowboard.Format([]byte("\n\n"))))
+	assert.Equal(t, string(b), string(snowboard.Format(b)))
+}
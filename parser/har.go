@@ -0,0 +1,122 @@
+package parser
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+type harHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harRequest struct {
+	Method      string       `json:"method"`
+	URL         string       `json:"url"`
+	HTTPVersion string       `json:"httpVersion"`
+	Cookies     []harHeader  `json:"cookies"`
+	Headers     []harHeader  `json:"headers"`
+	QueryString []harHeader  `json:"queryString"`
+	PostData    *harPostData `json:"postData,omitempty"`
+	HeadersSize int          `json:"headersSize"`
+	BodySize    int          `json:"bodySize"`
+}
+
+func harQueryString(s string) []harHeader {
+	hs := []harHeader{}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return hs
+	}
+
+	for _, q := range strings.Split(u.RawQuery, "&") {
+		if q == "" {
+			continue
+		}
+
+		kv := strings.SplitN(q, "=", 2)
+		k, _ := url.QueryUnescape(kv[0])
+		v := ""
+
+		if len(kv) == 2 {
+			v, _ = url.QueryUnescape(kv[1])
+		}
+
+		hs = append(hs, harHeader{Name: k, Value: v})
+	}
+
+	return hs
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+type harResponse struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []harHeader `json:"cookies"`
+	Headers     []harHeader `json:"headers"`
+	Content     harContent  `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+type harEntry struct {
+	StartedDateTime string                 `json:"startedDateTime"`
+	Time            float64                `json:"time"`
+	Request         harRequest             `json:"request"`
+	Response        harResponse            `json:"response"`
+	Cache           map[string]interface{} `json:"cache"`
+	Timings         harTimings             `json:"timings"`
+	Comment         string                 `json:"comment,omitempty"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+// harHeaders returns headers sorted by name
+func harHeaders(h http.Header) []harHeader {
+	hs := []harHeader{}
+
+	keys := []string{}
+	for k := range h {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		for _, v := range h[k] {
+			hs = append(hs, harHeader{Name: k, Value: v})
+		}
+	}
+
+	return hs
+}
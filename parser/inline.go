@@ -0,0 +1,172 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// AssetOpener returns content of asset identified by name
+type AssetOpener func(name string) ([]byte, error)
+
+var (
+	stylesheetPattern = regexp.MustCompile(`<link\s+[^>]*rel="stylesheet"[^>]*>`)
+	scriptPattern     = regexp.MustCompile(`<script\s+[^>]*src="[^"]*"[^>]*>\s*</script>`)
+	hrefPattern       = regexp.MustCompile(`href="([^"]*)"`)
+	srcPattern        = regexp.MustCompile(`src="([^"]*)"`)
+	cssImportPattern  = regexp.MustCompile(`@import\s+(?:url\()?["']?([^"')\s;]+)["']?\)?[^;]*;`)
+	cssURLPattern     = regexp.MustCompile(`url\(\s*["']?([^"')]+)["']?\s*\)`)
+)
+
+// Inline embeds stylesheets, scripts, fonts and images referenced by HTML
+// document so it can be viewed without network access. Stylesheets and scripts
+// which can not be opened are reported as error, while missing resources
+// referenced from stylesheets are kept as is.
+func Inline(b []byte, open AssetOpener) ([]byte, error) {
+	var err error
+
+	b = stylesheetPattern.ReplaceAllFunc(b, func(tag []byte) []byte {
+		if err != nil {
+			return tag
+		}
+
+		ms := hrefPattern.FindSubmatch(tag)
+		if ms == nil {
+			return tag
+		}
+
+		css, ferr := inlineStylesheet(string(ms[1]), open)
+		if ferr != nil {
+			err = ferr
+			return tag
+		}
+
+		return []byte("<style>\n" + css + "\n</style>")
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	b = scriptPattern.ReplaceAllFunc(b, func(tag []byte) []byte {
+		if err != nil {
+			return tag
+		}
+
+		ms := srcPattern.FindSubmatch(tag)
+		if ms == nil {
+			return tag
+		}
+
+		js, ferr := open(assetName(string(ms[1])))
+		if ferr != nil {
+			err = fmt.Errorf("Unable to inline %s: %s", ms[1], ferr)
+			return tag
+		}
+
+		js = bytes.Replace(js, []byte("</script"), []byte(`<\/script`), -1)
+		return []byte("<script type=\"text/javascript\">\n" + string(js) + "\n</script>")
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func inlineStylesheet(href string, open AssetOpener) (string, error) {
+	b, err := open(assetName(href))
+	if err != nil {
+		return "", fmt.Errorf("Unable to inline %s: %s", href, err)
+	}
+
+	css := cssImportPattern.ReplaceAllStringFunc(string(b), func(s string) string {
+		ms := cssImportPattern.FindStringSubmatch(s)
+
+		x, err := inlineStylesheet(resolveAsset(href, ms[1]), open)
+		if err != nil {
+			return s
+		}
+
+		return x
+	})
+
+	css = cssURLPattern.ReplaceAllStringFunc(css, func(s string) string {
+		ms := cssURLPattern.FindStringSubmatch(s)
+		if strings.HasPrefix(ms[1], "data:") || strings.HasPrefix(ms[1], "#") {
+			return s
+		}
+
+		name := resolveAsset(href, ms[1])
+
+		x, err := open(assetName(name))
+		if err != nil {
+			return s
+		}
+
+		return fmt.Sprintf(`url("data:%s;base64,%s")`, assetType(name), base64.StdEncoding.EncodeToString(x))
+	})
+
+	return css, nil
+}
+
+// resolveAsset resolves reference relative to the asset it's referenced from
+func resolveAsset(base, ref string) string {
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+
+	return b.ResolveReference(r).String()
+}
+
+// assetName strips scheme, query and fragment from asset URL. Protocol-relative
+// and absolute URLs are mapped to host/path, e.g. "cdnjs.cloudflare.com/ajax/..."
+func assetName(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return s
+	}
+
+	if u.Host != "" {
+		return path.Join(u.Host, u.Path)
+	}
+
+	return strings.TrimPrefix(u.Path, "/")
+}
+
+func assetType(name string) string {
+	ext := path.Ext(assetName(name))
+
+	switch ext {
+	case ".woff":
+		return "font/woff"
+	case ".woff2":
+		return "font/woff2"
+	case ".ttf":
+		return "font/ttf"
+	case ".otf":
+		return "font/otf"
+	case ".eot":
+		return "application/vnd.ms-fontobject"
+	case ".svg":
+		return "image/svg+xml"
+	}
+
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+
+	return "application/octet-stream"
+}
@@ -0,0 +1,59 @@
+package parser_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	snowboard "github.com/subosito/snowboard/parser"
+)
+
+func TestInline(t *testing.T) {
+	assets := map[string]string{
+		"cdn.example.com/ui/ui.min.css":        `@font-face{src:url(fonts/icons.woff?v=1)} .x{background:url("data:image/png;base64,AA==")}`,
+		"cdn.example.com/ui/fonts/icons.woff":  "font",
+		"cdn.example.com/ui/components/tab.js": `document.write("</script>")`,
+	}
+
+	open := func(name string) ([]byte, error) {
+		if s, ok := assets[name]; ok {
+			return []byte(s), nil
+		}
+
+		return nil, errors.New("not found")
+	}
+
+	s := `<link rel="stylesheet" href="//cdn.example.com/ui/ui.min.css">
+<script type="text/javascript" src="https://cdn.example.com/ui/components/tab.js"></script>`
+
+	b, err := snowboard.Inline([]byte(s), open)
+	assert.Nil(t, err)
+	assert.Contains(t, string(b), `url("data:font/woff;base64,Zm9udA==")`)
+	assert.Contains(t, string(b), `url("data:image/png;base64,AA==")`)
+	assert.Contains(t, string(b), `document.write("<\/script>")`)
+	assert.NotContains(t, string(b), "cdn.example.com")
+
+	_, err = snowboard.Inline([]byte(`<script src="//cdn.example.com/missing.js"></script>`), open)
+	assert.NotNil(t, err)
+}
+
+func TestInline_import(t *testing.T) {
+	assets := map[string]string{
+		"cdn.example.com/ui/ui.min.css":     `@import url(https://fonts.example.com/css?family=Lato);@import "theme.css";.x{}`,
+		"cdn.example.com/ui/theme.css":      `.theme{}`,
+		"fonts.example.com/css?family=Lato": "",
+	}
+
+	open := func(name string) ([]byte, error) {
+		if s, ok := assets[name]; ok && s != "" {
+			return []byte(s), nil
+		}
+
+		return nil, errors.New("not found")
+	}
+
+	b, err := snowboard.Inline([]byte(`<link rel="stylesheet" href="//cdn.example.com/ui/ui.min.css">`), open)
+	assert.Nil(t, err)
+	assert.Contains(t, string(b), `@import url(https://fonts.example.com/css?family=Lato);`)
+	assert.Contains(t, string(b), `.theme{}.x{}`)
+}
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"html/template"
+	"io"
+)
+
+// LandingPage links documentation of combined services
+type LandingPage struct {
+	Title    string
+	Services []LandingService
+}
+
+// LandingService is a service listed on landing page
+type LandingService struct {
+	MountedAPI
+	File string
+}
+
+// Endpoints returns number of transitions of service
+func (s LandingService) Endpoints() int {
+	return len(Endpoints(s.API))
+}
+
+// Landing renders landing page of combined documentation
+func Landing(w io.Writer, p LandingPage) error {
+	tpl, err := template.New("landing").Funcs(template.FuncMap{
+		"markdownize": markdownize,
+	}).Parse(landingTemplate)
+	if err != nil {
+		return err
+	}
+
+	return tpl.Execute(w, p)
+}
+
+const landingTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ if .Title }}{{ .Title }}{{ else }}API Documentation{{ end }}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 960px; color: #333; }
+.service { border: 1px solid #eee; border-radius: 4px; padding: 1em 1.5em; margin-bottom: 1em; }
+.service h2 { margin-top: 0; }
+.service h2 small { color: #888; font-weight: normal; font-size: 60%; }
+.meta { color: #888; }
+a { color: #0366d6; text-decoration: none; }
+</style>
+</head>
+<body>
+<h1>{{ if .Title }}{{ .Title }}{{ else }}API Documentation{{ end }}</h1>
+{{ range .Services }}
+<div class="service">
+<h2><a href="{{ .File }}">{{ if .API.Title }}{{ .API.Title }}{{ else }}{{ .Name }}{{ end }}</a> <small>{{ .Name }}</small></h2>
+{{ markdownize .API.Description }}
+<p class="meta">{{ .Endpoints }} endpoints{{ if .Prefix }}, mocked under <code>{{ .Prefix }}</code>{{ end }}</p>
+</div>
+{{ end }}
+</body>
+</html>
+`
@@ -0,0 +1,200 @@
+package parser
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/subosito/snowboard/api"
+)
+
+// LintRule is a style check of blueprint beyond what drafter validates
+type LintRule struct {
+	Name        string
+	Description string
+	check       func(b *api.API) []LintProblem
+}
+
+// LintProblem is a violation of lint rule, along with action it was found
+// in, if any
+type LintProblem struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Method   string `json:"method,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+// Lint severities
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// LintRules lists available lint rules
+var LintRules = []LintRule{
+	{
+		Name:        "api-host",
+		Description: "HOST metadata is documented",
+		check:       lintHost,
+	},
+	{
+		Name:        "action-title",
+		Description: "every action has a title",
+		check:       lintActionTitle,
+	},
+	{
+		Name:        "response-example",
+		Description: "successful responses have a body example, except 204 and HEAD",
+		check:       lintResponseExample,
+	},
+	{
+		Name:        "uri-parameter",
+		Description: "every URI template variable is described in parameters",
+		check:       lintURIParameter,
+	},
+}
+
+// ParseLintRules parses rules given as "name" or "name=severity" into
+// severities by rule name. Severity defaults to error, "off" disables the
+// rule.
+func ParseLintRules(specs []string) (map[string]string, error) {
+	rules := map[string]string{}
+
+	for _, spec := range specs {
+		name, severity := spec, LintError
+
+		if i := strings.Index(spec, "="); i != -1 {
+			name, severity = strings.TrimSpace(spec[:i]), strings.TrimSpace(spec[i+1:])
+		}
+
+		if !knownLintRule(name) {
+			return nil, fmt.Errorf("Unknown lint rule %q", name)
+		}
+
+		switch severity {
+		case LintError, LintWarning:
+			rules[name] = severity
+		case "off":
+			delete(rules, name)
+		default:
+			return nil, fmt.Errorf("Unknown severity %q of lint rule %s, expected error, warning or off", severity, name)
+		}
+	}
+
+	return rules, nil
+}
+
+func knownLintRule(name string) bool {
+	for _, r := range LintRules {
+		if r.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Lint checks blueprint against rules, given as severity by rule name.
+// Problems are ordered by rule.
+func Lint(b *api.API, rules map[string]string) []LintProblem {
+	ps := []LintProblem{}
+
+	for _, r := range LintRules {
+		severity, ok := rules[r.Name]
+		if !ok {
+			continue
+		}
+
+		for _, p := range r.check(b) {
+			p.Rule, p.Severity = r.Name, severity
+			ps = append(ps, p)
+		}
+	}
+
+	return ps
+}
+
+func lintHost(b *api.API) []LintProblem {
+	if b.Host() == "" {
+		return []LintProblem{{Message: "HOST is not documented"}}
+	}
+
+	return nil
+}
+
+func lintActionTitle(b *api.API) []LintProblem {
+	xs := []LintProblem{}
+
+	for _, e := range Endpoints(b) {
+		if strings.TrimSpace(e.Transition.Title) == "" {
+			xs = append(xs, lintAction(e.Transition, "action has no title"))
+		}
+	}
+
+	return xs
+}
+
+func lintResponseExample(b *api.API) []LintProblem {
+	xs := []LintProblem{}
+
+	for _, e := range Endpoints(b) {
+		t := e.Transition
+		seen := map[int]bool{}
+
+		for _, n := range t.Transactions {
+			code := n.Response.StatusCode
+
+			switch {
+			case seen[code], code < 200, code >= 300:
+				continue
+			case code == http.StatusNoContent, code == http.StatusResetContent:
+				continue
+			case n.Request.Method == http.MethodHead:
+				continue
+			}
+
+			seen[code] = true
+
+			if strings.TrimSpace(n.Response.Body.Body) == "" {
+				xs = append(xs, lintAction(t, fmt.Sprintf("response %d has no body example", code)))
+			}
+		}
+	}
+
+	return xs
+}
+
+func lintURIParameter(b *api.API) []LintProblem {
+	xs := []LintProblem{}
+
+	for _, e := range Endpoints(b) {
+		t := e.Transition
+		described := map[string]bool{}
+
+		for _, p := range e.Resource.Href.Parameters {
+			described[p.Key] = true
+		}
+
+		for _, p := range t.Href.Parameters {
+			described[p.Key] = true
+		}
+
+		for _, k := range uriVars(t.URL) {
+			if !described[k] {
+				described[k] = true
+				xs = append(xs, lintAction(t, "parameter "+k+" is not described"))
+			}
+		}
+	}
+
+	return xs
+}
+
+func lintAction(t *api.Transition, message string) LintProblem {
+	return LintProblem{
+		Message: t.Method + " " + t.URL + ": " + message,
+		Method:  t.Method,
+		URL:     t.URL,
+	}
+}
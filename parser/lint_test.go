@@ -0,0 +1,53 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/subosito/snowboard/api"
+	snowboard "github.com/subosito/snowboard/parser"
+)
+
+func TestParseLintRules(t *testing.T) {
+	rules, err := snowboard.ParseLintRules([]string{"action-title", "api-host=warning", "uri-parameter", "uri-parameter=off"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"action-title": "error", "api-host": "warning"}, rules)
+
+	_, err = snowboard.ParseLintRules([]string{"action-name"})
+	assert.NotNil(t, err)
+
+	_, err = snowboard.ParseLintRules([]string{"action-title=fatal"})
+	assert.NotNil(t, err)
+}
+
+func TestLint(t *testing.T) {
+	b := renderFixture()
+	b.Metadata = nil
+
+	r := b.ResourceGroups[0].Resources[0]
+	r.Transitions = append(r.Transitions, &api.Transition{
+		Method: "DELETE",
+		URL:    "https://api.example.com/notes/{id}{?force}",
+		Transactions: []api.Transaction{
+			{Request: api.Request{Method: "DELETE"}, Response: api.Response{StatusCode: 204}},
+			{Request: api.Request{Method: "DELETE"}, Response: api.Response{StatusCode: 202}},
+		},
+	})
+
+	all := map[string]string{}
+	for _, x := range snowboard.LintRules {
+		all[x.Name] = "warning"
+	}
+
+	ps := snowboard.Lint(b, all)
+	assert.Equal(t, []snowboard.LintProblem{
+		{Rule: "api-host", Severity: "warning", Message: "HOST is not documented"},
+		{Rule: "action-title", Severity: "warning", Message: "DELETE https://api.example.com/notes/{id}{?force}: action has no title", Method: "DELETE", URL: "https://api.example.com/notes/{id}{?force}"},
+		{Rule: "response-example", Severity: "warning", Message: "DELETE https://api.example.com/notes/{id}{?force}: response 202 has no body example", Method: "DELETE", URL: "https://api.example.com/notes/{id}{?force}"},
+		{Rule: "uri-parameter", Severity: "warning", Message: "DELETE https://api.example.com/notes/{id}{?force}: parameter id is not described", Method: "DELETE", URL: "https://api.example.com/notes/{id}{?force}"},
+		{Rule: "uri-parameter", Severity: "warning", Message: "DELETE https://api.example.com/notes/{id}{?force}: parameter force is not described", Method: "DELETE", URL: "https://api.example.com/notes/{id}{?force}"},
+	}, ps)
+
+	assert.Empty(t, snowboard.Lint(renderFixture(), all))
+	assert.Empty(t, snowboard.Lint(b, map[string]string{}))
+}
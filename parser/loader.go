@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -17,17 +18,30 @@ type loader struct {
 	name    string
 	seed    string
 	baseDir string
+	reader  io.Reader
 }
 
 func newLoader(name string) *loader {
-	d := &loader{name: name}
-	d.detectBaseDir()
+	return newSourceLoader(Source{Name: name})
+}
+
+func newSourceLoader(src Source) *loader {
+	d := &loader{name: src.Name, reader: src.Reader}
+
+	switch {
+	case src.BaseDir != "":
+		d.detectBaseDir(src.BaseDir)
+	case src.Reader != nil:
+		d.detectBaseDir(".")
+	default:
+		d.detectBaseDir(filepath.Dir(src.Name))
+	}
 
 	return d
 }
 
-func (d *loader) detectBaseDir() {
-	abs, err := filepath.Abs(filepath.Dir(d.name))
+func (d *loader) detectBaseDir(dir string) {
+	abs, err := filepath.Abs(dir)
 	if err == nil {
 		d.baseDir = abs
 	}
@@ -43,8 +57,16 @@ func (d *loader) partial(name string) string {
 }
 
 func (d *loader) read(name string) ([]byte, error) {
-	fname := filepath.Join(d.baseDir, name)
-	return ioutil.ReadFile(fname)
+	return ioutil.ReadFile(d.path(name))
+}
+
+// path resolves name relative to base directory
+func (d *loader) path(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+
+	return filepath.Join(d.baseDir, name)
 }
 
 func (d *loader) unmarshal(name string) (data map[string]interface{}, err error) {
@@ -103,13 +125,19 @@ func (d *loader) convert(s string) string {
 }
 
 func (d *loader) parse() (string, error) {
-	f, err := os.Open(d.name)
-	if err != nil {
-		return "", err
+	r := d.reader
+
+	if r == nil {
+		f, err := os.Open(d.name)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+
+		r = f
 	}
-	defer f.Close()
 
-	scanner := bufio.NewScanner(f)
+	scanner := bufio.NewScanner(r)
 	cs := []string{}
 
 	for scanner.Scan() {
@@ -136,21 +164,163 @@ func join(ss []interface{}, s string) string {
 	return strings.Join(xs, s)
 }
 
+// Source is API blueprint to be read from file, or from Reader when given.
+// Partials and seed are resolved relative to BaseDir, which defaults to
+// directory of the file, or working directory for Reader. Seed, when given,
+// replaces seed file referred by blueprint.
+type Source struct {
+	Name    string
+	Seed    string
+	BaseDir string
+	Reader  io.Reader
+}
+
 // Read reads API blueprint from file as bytes
 func Read(name string) ([]byte, error) {
-	d := newLoader(name)
+	return ReadSource(Source{Name: name})
+}
+
+// ReadSource reads API blueprint of source as bytes
+func ReadSource(src Source) ([]byte, error) {
+	d := newSourceLoader(src)
+	return d.render(src.Seed, d.partial)
+}
+
+// Expansion is API blueprint read from source along with origin of each of
+// its lines, so positions reported by parser can be mapped back to partials
+type Expansion struct {
+	Body    []byte
+	Origins []Origin
+}
+
+// Origin is a zero-based line of file
+type Origin struct {
+	File string
+	Line int
+}
+
+// markers of partial content, removed from expansion
+const (
+	partialBegin = "\x00partial:"
+	partialEnd   = "\x00end\x00"
+)
+
+// Expand reads API blueprint of source as ReadSource does, keeping track of
+// file and line every line comes from
+func Expand(src Source) (*Expansion, error) {
+	d := newSourceLoader(src)
+
+	b, err := d.render(src.Seed, func(name string) string {
+		s := d.partial(name)
+		if s == "" {
+			return ""
+		}
+
+		return partialBegin + d.path(name) + "\x00" + s + partialEnd
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := filepath.Abs(src.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return expand(b, name), nil
+}
+
+func expand(b []byte, name string) *Expansion {
+	x := &Expansion{}
+	stack := []Origin{{File: name}}
+	body := make([]byte, 0, len(b))
+	start := true
+
+	for len(b) > 0 {
+		if bytes.HasPrefix(b, []byte(partialBegin)) {
+			b = b[len(partialBegin):]
+
+			i := bytes.IndexByte(b, 0)
+			if i == -1 {
+				break
+			}
+
+			stack = append(stack, Origin{File: string(b[:i])})
+			b = b[i+1:]
+			continue
+		}
+
+		if bytes.HasPrefix(b, []byte(partialEnd)) {
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
 
+			b = b[len(partialEnd):]
+			continue
+		}
+
+		top := &stack[len(stack)-1]
+
+		if start {
+			x.Origins = append(x.Origins, *top)
+			start = false
+		}
+
+		body = append(body, b[0])
+
+		if b[0] == '\n' {
+			top.Line++
+			start = true
+		}
+
+		b = b[1:]
+	}
+
+	x.Body = body
+
+	return x
+}
+
+// Locate returns origin of byte offset of expanded body, along with byte
+// column within the line
+func (x *Expansion) Locate(offset int) (Origin, int) {
+	if offset > len(x.Body) {
+		offset = len(x.Body)
+	}
+
+	line := bytes.Count(x.Body[:offset], []byte("\n"))
+	column := offset - (bytes.LastIndexByte(x.Body[:offset], '\n') + 1)
+
+	if line >= len(x.Origins) {
+		if len(x.Origins) == 0 {
+			return Origin{}, column
+		}
+
+		o := x.Origins[len(x.Origins)-1]
+		o.Line += line - len(x.Origins) + 1
+
+		return o, column
+	}
+
+	return x.Origins[line], column
+}
+
+func (d *loader) render(seed string, partial func(string) string) ([]byte, error) {
 	s, err := d.parse()
 	if err != nil {
 		return nil, err
 	}
 
+	if seed != "" {
+		d.seed = seed
+	}
+
 	data, err := d.loadSeed()
 	if err != nil {
 		return nil, err
 	}
 
-	b, _ := process(s, data, template.FuncMap{"partial": d.partial})
+	b, _ := process(s, data, template.FuncMap{"partial": partial})
 
 	funcMap := template.FuncMap{
 		"upcase": strings.ToUpper,
@@ -165,6 +335,37 @@ func Read(name string) ([]byte, error) {
 	return b, nil
 }
 
+// Files returns absolute paths of API blueprint file along with partials and
+// seed file it refers to
+func Files(name string) ([]string, error) {
+	d := newLoader(name)
+
+	s, err := d.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := []string{abs}
+
+	if d.seed != "" {
+		fs = append(fs, filepath.Join(d.baseDir, d.seed))
+	}
+
+	partial := func(name string) string {
+		fs = append(fs, filepath.Join(d.baseDir, name))
+		return ""
+	}
+
+	process(s, nil, template.FuncMap{"partial": partial})
+
+	return fs, nil
+}
+
 func process(s string, data interface{}, funcMap template.FuncMap) ([]byte, error) {
 	tmpl, err := template.New("apib").Funcs(funcMap).Parse(s)
 	if err != nil {
@@ -0,0 +1,230 @@
+package parser
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/subosito/snowboard/api"
+)
+
+// Service is an API blueprint combined with others, mounted under Prefix in
+// mock server and rendered as its own page of documentation
+type Service struct {
+	Name   string `toml:"name"`
+	Input  string `toml:"input"`
+	Prefix string `toml:"prefix"`
+}
+
+// Manifest lists services combined into single documentation and mock
+// server, written in TOML
+type Manifest struct {
+	Title    string    `toml:"title"`
+	Services []Service `toml:"service"`
+}
+
+// LoadManifest reads manifest file. Inputs are resolved relative to the
+// manifest.
+func LoadManifest(name string) (*Manifest, error) {
+	m := &Manifest{}
+
+	if _, err := toml.DecodeFile(name, m); err != nil {
+		return nil, fmt.Errorf("Unable to read manifest %s: %s", name, err)
+	}
+
+	for i, s := range m.Services {
+		if s.Input == "" {
+			return nil, fmt.Errorf("Unable to read manifest %s: service #%d has no input", name, i+1)
+		}
+
+		if !filepath.IsAbs(s.Input) {
+			m.Services[i].Input = filepath.Join(filepath.Dir(name), s.Input)
+		}
+	}
+
+	if err := m.normalize(); err != nil {
+		return nil, fmt.Errorf("Unable to read manifest %s: %s", name, err)
+	}
+
+	return m, nil
+}
+
+// NewManifest returns manifest of inputs, each named and prefixed after its
+// file name, or its directory when file name is generic such as API.apib
+func NewManifest(inputs []string) (*Manifest, error) {
+	m := &Manifest{}
+
+	for _, input := range inputs {
+		m.Services = append(m.Services, Service{Input: input})
+	}
+
+	if err := m.normalize(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// normalize fills missing names and prefixes, then checks they are unique
+func (m *Manifest) normalize() error {
+	names := map[string]bool{}
+	prefixes := map[string]string{}
+
+	for i := range m.Services {
+		s := &m.Services[i]
+
+		if s.Name == "" {
+			s.Name = serviceName(s.Input)
+		}
+
+		if s.Prefix == "" {
+			s.Prefix = "/" + s.Name
+		}
+
+		s.Prefix = strings.TrimSuffix(path.Join("/", s.Prefix), "/")
+
+		if names[s.Name] {
+			return fmt.Errorf("duplicate service name %q, name services in manifest", s.Name)
+		}
+
+		if other, ok := prefixes[s.Prefix]; ok {
+			return fmt.Errorf("services %s and %s share prefix %q", other, s.Name, s.Prefix)
+		}
+
+		names[s.Name] = true
+		prefixes[s.Prefix] = s.Name
+	}
+
+	return nil
+}
+
+func serviceName(input string) string {
+	base := filepath.Base(input)
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+
+	switch strings.ToLower(name) {
+	case "api", "index", "apiary", "blueprint":
+		if abs, err := filepath.Abs(input); err == nil {
+			name = filepath.Base(filepath.Dir(abs))
+		}
+	}
+
+	return parameterize(name)
+}
+
+// MountedAPI is a parsed blueprint of service
+type MountedAPI struct {
+	Service
+	API *api.API
+}
+
+var routeParamPattern = regexp.MustCompile(`:\w+|\{[^}]*\}`)
+
+// mountedRoute is a route of mock transaction along with its service
+type mountedRoute struct {
+	owner    string
+	method   string
+	pattern  string
+	segments []string
+}
+
+// MockServices returns mock transactions of every service mounted under its
+// prefix. Routes which more than one service would serve, or which one
+// service's static path shadows in another one's parameter, are reported as
+// conflicts.
+func MockServices(xs []MountedAPI) (MockTransactions, error) {
+	ms := MockTransactions{}
+	owners := map[string]string{}
+	routes := []mountedRoute{}
+	conflicts := []string{}
+
+	for _, x := range xs {
+		seen := map[string]bool{}
+
+		for _, m := range Mock(x.API) {
+			m.Path = mountPath(x.Prefix, m.Path)
+			m.Pattern = mountPath(x.Prefix, m.Pattern)
+
+			// parameter names don't matter for routing
+			p := routeParamPattern.ReplaceAllString(m.Path, ":")
+			key := m.Method + " " + p
+
+			if owner, ok := owners[key]; ok && owner != x.Name && !seen[key] {
+				conflicts = append(conflicts, fmt.Sprintf("%s %s is served by %s and %s", m.Method, m.Pattern, owner, x.Name))
+			}
+
+			if !seen[key] && !strings.Contains(m.Path, "{") {
+				routes = append(routes, mountedRoute{owner: x.Name, method: m.Method, pattern: m.Pattern, segments: strings.Split(p, "/")})
+			}
+
+			owners[key] = x.Name
+			seen[key] = true
+
+			ms = append(ms, m)
+		}
+	}
+
+	for i, a := range routes {
+		for _, b := range routes[i+1:] {
+			if a.owner == b.owner || a.method != b.method {
+				continue
+			}
+
+			switch routeOverlap(a.segments, b.segments) {
+			case -1:
+				conflicts = append(conflicts, fmt.Sprintf("%s %s of %s shadows %s of %s", a.method, a.pattern, a.owner, b.pattern, b.owner))
+			case 1:
+				conflicts = append(conflicts, fmt.Sprintf("%s %s of %s shadows %s of %s", b.method, b.pattern, b.owner, a.pattern, a.owner))
+			}
+		}
+	}
+
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return nil, fmt.Errorf("Route conflicts:\n  %s", strings.Join(conflicts, "\n  "))
+	}
+
+	return ms, nil
+}
+
+// routeOverlap compares segments of routes, where parameters are ":" and
+// static segments take precedence. It returns -1 when a static segment of a
+// shadows parameter of b, 1 when b's shadows a's, and 0 when no path matches
+// both routes or they are equal.
+func routeOverlap(a, b []string) int {
+	if len(a) != len(b) {
+		return 0
+	}
+
+	winner := 0
+
+	for i := range a {
+		switch {
+		case a[i] == b[i]:
+		case a[i] == ":" && winner == 0:
+			winner = 1
+		case b[i] == ":" && winner == 0:
+			winner = -1
+		case a[i] != ":" && b[i] != ":":
+			return 0
+		}
+	}
+
+	return winner
+}
+
+func mountPath(prefix, p string) string {
+	if prefix == "" || prefix == "/" {
+		return p
+	}
+
+	if p == "/" {
+		return prefix
+	}
+
+	return prefix + p
+}
@@ -0,0 +1,112 @@
+package parser_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/subosito/snowboard/api"
+	snowboard "github.com/subosito/snowboard/parser"
+)
+
+func TestLoadManifest(t *testing.T) {
+	m, err := snowboard.LoadManifest("../fixtures/manifest/services.toml")
+	assert.Nil(t, err)
+	assert.Equal(t, "Acme APIs", m.Title)
+	assert.Equal(t, []snowboard.Service{
+		{Name: "notes", Input: filepath.Join("../fixtures/manifest", "notes.apib"), Prefix: "/notes"},
+		{Name: "users", Input: filepath.Join("../fixtures/manifest", "users/API.apib"), Prefix: "/people"},
+	}, m.Services)
+}
+
+func TestNewManifest(t *testing.T) {
+	m, err := snowboard.NewManifest([]string{"billing.apib", "users/API.apib"})
+	assert.Nil(t, err)
+	assert.Equal(t, "billing", m.Services[0].Name)
+	assert.Equal(t, "/users", m.Services[1].Prefix)
+
+	_, err = snowboard.NewManifest([]string{"a/notes.apib", "b/notes.apib"})
+	assert.NotNil(t, err)
+}
+
+func TestMockServices(t *testing.T) {
+	notes := renderFixture()
+	users := &api.API{
+		ResourceGroups: []api.ResourceGroup{
+			{
+				Resources: []*api.Resource{
+					{
+						Transitions: []*api.Transition{
+							{
+								URL:          "/",
+								Transactions: []api.Transaction{{Request: api.Request{Method: "GET"}, Response: api.Response{StatusCode: 200}}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ms, err := snowboard.MockServices([]snowboard.MountedAPI{
+		{Service: snowboard.Service{Name: "notes", Prefix: "/notes-api"}, API: notes},
+		{Service: snowboard.Service{Name: "users", Prefix: "/users"}, API: users},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, ms, 2)
+	assert.Equal(t, "/notes-api/notes/:id", ms[0].Path)
+	assert.Equal(t, "/users", ms[1].Path)
+
+	users.ResourceGroups[0].Resources[0].Transitions[0].URL = "/notes/{name}"
+
+	_, err = snowboard.MockServices([]snowboard.MountedAPI{
+		{Service: snowboard.Service{Name: "notes"}, API: notes},
+		{Service: snowboard.Service{Name: "users"}, API: users},
+	})
+	assert.EqualError(t, err, "Route conflicts:\n  GET /notes/:name is served by notes and users")
+
+	users.ResourceGroups[0].Resources[0].Transitions[0].URL = "/{id}"
+	admin := &api.API{
+		ResourceGroups: []api.ResourceGroup{
+			{
+				Resources: []*api.Resource{
+					{
+						Transitions: []*api.Transition{
+							{
+								URL:          "/",
+								Transactions: []api.Transaction{{Request: api.Request{Method: "GET"}, Response: api.Response{StatusCode: 200}}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	_, err = snowboard.MockServices([]snowboard.MountedAPI{
+		{Service: snowboard.Service{Name: "users", Prefix: "/users"}, API: users},
+		{Service: snowboard.Service{Name: "admin", Prefix: "/users/admin"}, API: admin},
+	})
+	assert.EqualError(t, err, "Route conflicts:\n  GET /users/admin of admin shadows /users/:id of users")
+
+	_, err = snowboard.MockServices([]snowboard.MountedAPI{
+		{Service: snowboard.Service{Name: "users", Prefix: "/users"}, API: users},
+		{Service: snowboard.Service{Name: "admin", Prefix: "/users/admin/panel"}, API: admin},
+	})
+	assert.Nil(t, err)
+}
+
+func TestLanding(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := snowboard.Landing(&buf, snowboard.LandingPage{
+		Title: "Acme APIs",
+		Services: []snowboard.LandingService{
+			{MountedAPI: snowboard.MountedAPI{Service: snowboard.Service{Name: "notes", Prefix: "/notes"}, API: renderFixture()}, File: "notes.html"},
+		},
+	})
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), `<a href="notes.html">Notes API</a>`)
+	assert.Contains(t, buf.String(), "1 endpoints, mocked under <code>/notes</code>")
+}
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+type markdownDialect struct{}
+
+func (markdownDialect) ext() string {
+	return ".md"
+}
+
+func (markdownDialect) title(s string) string {
+	return "# " + s + "\n"
+}
+
+func (markdownDialect) heading(level int, s, anchor string) string {
+	return fmt.Sprintf("<a id=\"%s\"></a>\n%s %s\n", anchor, strings.Repeat("#", level), s)
+}
+
+func (markdownDialect) link(s, file, anchor string) string {
+	return fmt.Sprintf("[%s](%s#%s)", s, file, anchor)
+}
+
+func (markdownDialect) item(level int, s string) string {
+	return strings.Repeat("  ", level) + "- " + s
+}
+
+func (markdownDialect) table(header []string, rows [][]string) string {
+	xs := []string{markdownRow(header)}
+
+	ds := make([]string, len(header))
+	for i := range ds {
+		ds[i] = "---"
+	}
+
+	xs = append(xs, markdownRow(ds))
+
+	for _, r := range rows {
+		xs = append(xs, markdownRow(r))
+	}
+
+	return strings.Join(xs, "\n") + "\n"
+}
+
+func (markdownDialect) code(lang, body string) string {
+	fence := "```"
+	for strings.Contains(body, fence) {
+		fence += "`"
+	}
+
+	return fmt.Sprintf("%s%s\n%s\n%s\n", fence, lang, body, fence)
+}
+
+func (markdownDialect) strong(s string) string {
+	return "**" + s + "**"
+}
+
+func (markdownDialect) literal(s string) string {
+	if strings.Contains(s, "`") {
+		return "`` " + s + " ``"
+	}
+
+	return "`" + s + "`"
+}
+
+func (markdownDialect) copy(s string) string {
+	return s
+}
+
+func markdownRow(cs []string) string {
+	xs := make([]string, len(cs))
+
+	for i, c := range cs {
+		c = strings.Replace(c, "|", `\|`, -1)
+		xs[i] = strings.Join(strings.Fields(c), " ")
+	}
+
+	return "| " + strings.Join(xs, " | ") + " |"
+}
@@ -1,15 +1,17 @@
 package parser
 
 import (
+	"bytes"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"net/url"
 	"path"
 	"regexp"
-	"strconv"
+	"sort"
 	"strings"
+	"sync"
+	"text/template"
 
 	"github.com/naoina/denco"
 	"github.com/subosito/snowboard/api"
@@ -19,9 +21,160 @@ type MockTransaction struct {
 	Path        string
 	Pattern     string
 	Method      string
+	Title       string
 	StatusCode  int
 	ContentType string
 	Body        string
+	Headers     []api.Header
+	Query       []MockParam
+
+	once sync.Once
+	tpl  *template.Template
+	err  error
+}
+
+// Render returns response body for request r. Bodies containing "<%" are
+// executed as templates having the request as data.
+func (m *MockTransaction) Render(r *http.Request, params map[string]string) (string, error) {
+	if !isMockTemplate(m.Body) {
+		return m.Body, nil
+	}
+
+	m.once.Do(func() {
+		m.tpl, m.err = parseMockTemplate(m.Method+" "+m.Path, m.Body)
+	})
+
+	if m.err != nil {
+		return "", m.err
+	}
+
+	req, err := newMockRequest(r, params)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+
+	if err := m.tpl.Execute(&buf, req); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// RenderHeaders returns response headers for request r. Path parameters
+// such as "{id}" are substituted, values containing "<%" are executed as
+// templates like the body.
+func (m *MockTransaction) RenderHeaders(r *http.Request, params map[string]string) (http.Header, error) {
+	h := http.Header{}
+
+	for _, x := range m.Headers {
+		k := http.CanonicalHeaderKey(x.Key)
+
+		// these are computed by server
+		if k == "Content-Length" || k == "Transfer-Encoding" {
+			continue
+		}
+
+		v := uriSimpleExpressionPattern.ReplaceAllStringFunc(x.Value, func(s string) string {
+			if p, ok := params[s[1:len(s)-1]]; ok {
+				return url.PathEscape(p)
+			}
+
+			return s
+		})
+
+		if isMockTemplate(v) {
+			tpl, err := parseMockTemplate(k, v)
+			if err != nil {
+				return nil, err
+			}
+
+			req, err := newMockRequest(r, params)
+			if err != nil {
+				return nil, err
+			}
+
+			var buf bytes.Buffer
+
+			if err := tpl.Execute(&buf, req); err != nil {
+				return nil, err
+			}
+
+			v = buf.String()
+		}
+
+		h.Add(k, v)
+	}
+
+	return h, nil
+}
+
+// MockParam is a query parameter of mock transaction
+type MockParam struct {
+	Key      string
+	Required bool
+	Example  string
+}
+
+// mockQuery returns query parameters of URI template u described by params
+func mockQuery(u string, params []api.Parameter) []MockParam {
+	xs := []MockParam{}
+
+	for _, k := range uriQueryVars(u) {
+		x := MockParam{Key: k}
+
+		for _, p := range params {
+			if p.Key == k {
+				x.Required = p.Required
+				x.Example = p.Value
+			}
+		}
+
+		xs = append(xs, x)
+	}
+
+	return xs
+}
+
+// missing returns required query parameters absent from q
+func (m *MockTransaction) missing(q url.Values) []string {
+	xs := []string{}
+
+	for _, p := range m.Query {
+		if _, ok := q[p.Key]; p.Required && !ok {
+			xs = append(xs, p.Key)
+		}
+	}
+
+	return xs
+}
+
+// score rates how well query q fits the transaction. Documented parameters
+// present in q count once and values equal to documented examples count
+// twice, while parameters whose example is not matched count against.
+func (m *MockTransaction) score(q url.Values) int {
+	n := 0
+
+	for _, p := range m.Query {
+		_, ok := q[p.Key]
+
+		if ok {
+			n++
+		}
+
+		if p.Example == "" {
+			continue
+		}
+
+		if ok && q.Get(p.Key) == p.Example {
+			n += 2
+		} else {
+			n--
+		}
+	}
+
+	return n
 }
 
 type mockRecord struct {
@@ -31,7 +184,15 @@ type mockRecord struct {
 }
 
 type mockRouter struct {
-	routers map[string]*denco.Router
+	routers   map[string]*denco.Router
+	templates map[string][]*mockTemplate
+}
+
+// mockTemplate is a route whose URI template can't be expressed as router
+// pattern, e.g. "/files{/path*}"
+type mockTemplate struct {
+	matcher *uriMatcher
+	record  *mockRecord
 }
 
 func (mr mockRouter) Router(method string) *denco.Router {
@@ -42,6 +203,77 @@ func (mr mockRouter) Router(method string) *denco.Router {
 	return nil
 }
 
+// lookup finds route of method and path along with its path parameters.
+// HEAD requests fall back to GET routes when HEAD is not documented.
+func (mr mockRouter) lookup(method, path string) (*mockRecord, map[string]string, bool) {
+	if m, vars, ok := mr.find(method, path); ok {
+		return m, vars, true
+	}
+
+	if method == http.MethodHead {
+		return mr.find(http.MethodGet, path)
+	}
+
+	return nil, nil, false
+}
+
+func (mr mockRouter) find(method, path string) (*mockRecord, map[string]string, bool) {
+	if r := mr.Router(method); r != nil {
+		if data, ps, found := r.Lookup(path); found {
+			vars := map[string]string{}
+			for _, p := range ps {
+				vars[p.Name] = p.Value
+			}
+
+			return data.(*mockRecord), vars, true
+		}
+	}
+
+	for _, t := range mr.templates[method] {
+		if vars, ok := t.matcher.match(path); ok {
+			return t.record, vars, true
+		}
+	}
+
+	return nil, nil, false
+}
+
+// allow returns sorted methods having route of path, including derived HEAD
+// and OPTIONS. It returns nil when path has no route at all.
+func (mr mockRouter) allow(path string) []string {
+	seen := map[string]bool{}
+
+	for method := range mr.routers {
+		if _, _, found := mr.find(method, path); found {
+			seen[method] = true
+		}
+	}
+
+	for method := range mr.templates {
+		if _, _, found := mr.find(method, path); found {
+			seen[method] = true
+		}
+	}
+
+	if len(seen) == 0 {
+		return nil
+	}
+
+	if seen[http.MethodGet] {
+		seen[http.MethodHead] = true
+	}
+
+	seen[http.MethodOptions] = true
+
+	ms := []string{}
+	for m := range seen {
+		ms = append(ms, m)
+	}
+
+	sort.Strings(ms)
+	return ms
+}
+
 type MockTransactions []*MockTransaction
 
 func (ms MockTransactions) Router() *mockRouter {
@@ -62,8 +294,29 @@ func (ms MockTransactions) Router() *mockRouter {
 	}
 
 	mc := map[string][]denco.Record{}
+	mt := map[string][]*mockTemplate{}
+
+	keys := []string{}
+	for k := range mr {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		m := mr[k]
+
+		if strings.Contains(m.Pattern, "{") {
+			x, err := compileURIMatcher(m.Pattern)
+			if err != nil {
+				log.Printf("Unable to route %s %s: %s\n", m.Method, m.Pattern, err)
+				continue
+			}
+
+			mt[m.Method] = append(mt[m.Method], &mockTemplate{matcher: x, record: m})
+			continue
+		}
 
-	for _, m := range mr {
 		d := denco.Record{
 			Key:   m.Pattern,
 			Value: m,
@@ -84,7 +337,7 @@ func (ms MockTransactions) Router() *mockRouter {
 		mx[k] = r
 	}
 
-	return &mockRouter{mx}
+	return &mockRouter{routers: mx, templates: mt}
 }
 
 func Mock(b *api.API) []*MockTransaction {
@@ -94,14 +347,16 @@ func Mock(b *api.API) []*MockTransaction {
 		for _, x := range g.Resources {
 			for _, t := range x.Transitions {
 				for _, n := range t.Transactions {
-					p := transformURL(t.URL, b.Host())
 					m := &MockTransaction{
-						Path:        urlPath(p),
-						Pattern:     p,
+						Path:        routeKey(t.URL, b.Host()),
+						Pattern:     transformURL(t.URL, b.Host()),
 						Method:      n.Request.Method,
+						Title:       n.Request.Title,
 						StatusCode:  n.Response.StatusCode,
 						ContentType: n.Response.Body.ContentType,
 						Body:        n.Response.Body.Body,
+						Headers:     n.Response.Headers,
+						Query:       mockQuery(t.URL, t.Href.Parameters),
 					}
 
 					ms = append(ms, m)
@@ -113,52 +368,20 @@ func Mock(b *api.API) []*MockTransaction {
 	return ms
 }
 
+// MockHandler returns handler serving mock responses of transactions
 func MockHandler(ms MockTransactions) http.Handler {
-	fn := func(w http.ResponseWriter, r *http.Request) {
-		var n *MockTransaction
-
-		z := ms.Router()
-		router := z.Router(r.Method)
-		data, _, found := router.Lookup(r.URL.Path)
-		if !found {
-			w.WriteHeader(http.StatusNotFound)
-			return
-		}
-
-		m := data.(*mockRecord)
-		s := r.Header.Get("X-Status-Code")
-
-		if s == "" {
-			for _, t := range m.Transactions {
-				if t.StatusCode >= http.StatusOK && t.StatusCode < http.StatusBadRequest {
-					n = t
-				}
-			}
-		} else {
-			for _, t := range m.Transactions {
-				if s == strconv.Itoa(t.StatusCode) {
-					n = t
-				}
-			}
-		}
-
-		if n == nil {
-			w.WriteHeader(http.StatusNotFound)
-			return
-		}
-
-		log.Printf("%s\t%d\t%s\n", n.Method, n.StatusCode, n.Path)
-
-		w.Header().Set("Content-Type", n.ContentType)
-		w.WriteHeader(n.StatusCode)
-		io.WriteString(w, n.Body)
-	}
+	s, _ := NewMockServer(ms, nil)
+	return s
+}
 
-	return http.HandlerFunc(fn)
+// RoutePattern converts URI template of transition into router pattern, e.g.
+// "https://example.com/notes/{id}{?limit}" into "/notes/:id"
+func RoutePattern(u, host string) string {
+	return transformURL(u, host)
 }
 
 func transformURL(u, h string) string {
-	paramPattern := regexp.MustCompile(`\{\?[\w,]+\}`)
+	paramPattern := regexp.MustCompile(`\{[?&][^}]*\}`)
 	queryPattern := regexp.MustCompile(`\{([\w,]+)\}`)
 
 	u = queryPattern.ReplaceAllString(u, ":${1}")
@@ -0,0 +1,613 @@
+package parser_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/subosito/snowboard/api"
+	snowboard "github.com/subosito/snowboard/parser"
+)
+
+func mockFixture() []*snowboard.MockTransaction {
+	return []*snowboard.MockTransaction{
+		{Path: "/notes", Pattern: "/notes", Method: "POST", StatusCode: 201, ContentType: "application/json", Body: `{"id": 1}`},
+		{Path: "/notes/:id", Pattern: "/notes/:id", Method: "GET", StatusCode: 200, ContentType: "application/json", Body: `{"id": 1}`},
+	}
+}
+
+func TestMockHandler_template(t *testing.T) {
+	ms := mockFixture()
+	ms[1].Body = `{"id": <% .Param "id" %>, "email": "<% email %>", "n": <% int 5 5 %>}`
+
+	w := httptest.NewRecorder()
+	snowboard.MockHandler(ms).ServeHTTP(w, httptest.NewRequest("GET", "/notes/42", nil))
+
+	v := map[string]interface{}{}
+	assert.Equal(t, 200, w.Code)
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &v))
+	assert.Equal(t, float64(42), v["id"])
+	assert.Equal(t, float64(5), v["n"])
+	assert.Contains(t, v["email"], "@example.")
+}
+
+func TestMockHandler_templateError(t *testing.T) {
+	ms := mockFixture()
+	ms[1].Body = `<% .Unknown %>`
+
+	w := httptest.NewRecorder()
+	snowboard.MockHandler(ms).ServeHTTP(w, httptest.NewRequest("GET", "/notes/42", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestMockHandler_templateLargeBody(t *testing.T) {
+	ms := mockFixture()
+	ms[0].Body = `{"title": "<% .Field "title" %>"}`
+
+	w := httptest.NewRecorder()
+	snowboard.MockHandler(ms).ServeHTTP(w, httptest.NewRequest("POST", "/notes", strings.NewReader(strings.Repeat("a", 1<<20+1))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	w = httptest.NewRecorder()
+	snowboard.MockHandler(ms).ServeHTTP(w, httptest.NewRequest("POST", "/notes", strings.NewReader(`{"title": "hi"}`)))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"title": "hi"}`, w.Body.String())
+}
+
+func TestLoadMockConfig(t *testing.T) {
+	c, err := snowboard.LoadMockConfig("../fixtures/mock/notes.toml")
+	assert.Nil(t, err)
+	assert.Len(t, c.Routes, 3)
+
+	ms := c.Apply(mockFixture())
+	assert.Len(t, ms, 3)
+	h := snowboard.MockHandler(ms)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/notes", strings.NewReader(`{"title": "Say \"hi\""}`)))
+
+	v := map[string]string{}
+	assert.Equal(t, 201, w.Code)
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &v))
+	assert.Equal(t, `Say "hi"`, v["title"])
+	assert.Len(t, v["id"], 36)
+
+	r := httptest.NewRequest("GET", "/notes/7?tag=go", nil)
+	r.Header.Set("User-Agent", "test")
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, `{"id": 7, "tag": "go", "agent": "test"}`, strings.TrimSpace(w.Body.String()))
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/users", nil))
+
+	users := []map[string]interface{}{}
+	assert.Equal(t, 200, w.Code)
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &users))
+	assert.Len(t, users, 3)
+}
+
+func TestMockServer_chaos(t *testing.T) {
+	ms := mockFixture()
+	ms = append(ms, &snowboard.MockTransaction{Path: "/notes/:id", Pattern: "/notes/:id", Method: "GET", StatusCode: 503, Body: "unavailable"})
+
+	s, err := snowboard.NewMockServer(ms, &snowboard.MockConfig{
+		Chaos: []snowboard.MockChaos{
+			{Latency: "20ms"},
+			{Method: "GET", Path: "/notes/{id}", FailureRate: 1, RateLimit: 2, RateWindow: "1m"},
+		},
+	})
+	assert.Nil(t, err)
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("POST", "/notes", nil))
+	assert.Equal(t, 201, w.Code)
+	assert.True(t, time.Since(start) >= 20*time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		w = httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest("GET", "/notes/1", nil))
+		assert.Equal(t, 503, w.Code)
+		assert.Equal(t, "unavailable", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("GET", "/notes/1", nil))
+	assert.Equal(t, 429, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+
+	_, err = snowboard.NewMockServer(ms, &snowboard.MockConfig{Chaos: []snowboard.MockChaos{{Latency: "2s-1s"}}})
+	assert.NotNil(t, err)
+}
+
+func TestMockServer_chaosHeaders(t *testing.T) {
+	s, err := snowboard.NewMockServer(mockFixture(), nil)
+	assert.Nil(t, err)
+
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	r, _ := http.NewRequest("GET", ts.URL+"/notes/1", nil)
+	r.Header.Set("X-Mock-Drop-Rate", "1")
+	_, err = http.DefaultClient.Do(r)
+	assert.NotNil(t, err)
+
+	r, _ = http.NewRequest("GET", ts.URL+"/notes/1", nil)
+	r.Header.Set("X-Mock-Truncate-Rate", "1")
+	resp, err := http.DefaultClient.Do(r)
+	assert.Nil(t, err)
+
+	_, err = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.NotNil(t, err)
+
+	r, _ = http.NewRequest("GET", ts.URL+"/notes/1", nil)
+	r.Header.Set("X-Mock-Delay", "30")
+	start := time.Now()
+	resp, err = http.DefaultClient.Do(r)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.True(t, time.Since(start) >= 30*time.Millisecond)
+}
+
+func TestMockServer_admin(t *testing.T) {
+	loads := 0
+	s, err := snowboard.LoadMockServer(func() (snowboard.MockTransactions, *snowboard.MockConfig, error) {
+		loads++
+		return mockFixture(), nil, nil
+	})
+	assert.Nil(t, err)
+
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+
+	w := do("GET", "/__snowboard/routes", "")
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), `"path": "/notes/:id"`)
+
+	w = do("POST", "/__snowboard/next", `{"method": "GET", "path": "/notes/{id}", "status": 404}`)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, 404, do("GET", "/notes/1", "").Code)
+	assert.Equal(t, 200, do("GET", "/notes/1", "").Code)
+
+	w = do("POST", "/__snowboard/next", `{"path": "/unknown", "status": 404}`)
+	assert.Equal(t, 404, w.Code)
+
+	w = do("GET", "/__snowboard/requests", "")
+	entries := []snowboard.MockLogEntry{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &entries))
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "/notes/:id", entries[0].Route)
+	assert.Equal(t, 404, entries[0].StatusCode)
+	assert.True(t, entries[0].Forced)
+
+	assert.Equal(t, 405, do("GET", "/__snowboard/reset", "").Code)
+	assert.Equal(t, 200, do("POST", "/__snowboard/reset", "").Code)
+	assert.Equal(t, "[]\n", do("GET", "/__snowboard/requests", "").Body.String())
+
+	assert.Equal(t, 200, do("POST", "/__snowboard/reload", "").Code)
+	assert.Equal(t, 2, loads)
+}
+
+func TestMockServer_methods(t *testing.T) {
+	s, err := snowboard.NewMockServer(mockFixture(), nil)
+	assert.Nil(t, err)
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("HEAD", "/notes/1", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "9", w.Header().Get("Content-Length"))
+	assert.Equal(t, "", w.Body.String())
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("OPTIONS", "/notes/1", nil))
+	assert.Equal(t, 204, w.Code)
+	assert.Equal(t, "GET, HEAD, OPTIONS", w.Header().Get("Allow"))
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("DELETE", "/notes", nil))
+	assert.Equal(t, 405, w.Code)
+	assert.Equal(t, "OPTIONS, POST", w.Header().Get("Allow"))
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("GET", "/unknown", nil))
+	assert.Equal(t, 404, w.Code)
+}
+
+func benchmarkMockServer(b *testing.B, n int) {
+	ms := []*snowboard.MockTransaction{}
+
+	for i := 0; i < n; i++ {
+		p := fmt.Sprintf("/resources%d/:id", i)
+		ms = append(ms, &snowboard.MockTransaction{Path: p, Pattern: p, Method: "GET", StatusCode: 200, ContentType: "application/json", Body: `{"id": 1}`})
+	}
+
+	s, err := snowboard.NewMockServer(ms, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	r := httptest.NewRequest("GET", fmt.Sprintf("/resources%d/1", n/2), nil)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		s.ServeHTTP(httptest.NewRecorder(), r)
+	}
+}
+
+func BenchmarkMockServer_10(b *testing.B) {
+	benchmarkMockServer(b, 10)
+}
+
+func BenchmarkMockServer_100(b *testing.B) {
+	benchmarkMockServer(b, 100)
+}
+
+func BenchmarkMockServer_1000(b *testing.B) {
+	benchmarkMockServer(b, 1000)
+}
+
+func TestMock_uriTemplate(t *testing.T) {
+	tr := func(title, u string, ps []api.Parameter, code int, body string) *api.Transition {
+		return &api.Transition{
+			Title:  title,
+			Method: "GET",
+			URL:    u,
+			Href:   api.Href{Parameters: ps},
+			Transactions: []api.Transaction{
+				{Request: api.Request{Method: "GET"}, Response: api.Response{StatusCode: code, Body: api.Asset{ContentType: "text/plain", Body: body}}},
+			},
+		}
+	}
+
+	b := &api.API{
+		Metadata: []api.Metadata{{Key: "HOST", Value: "https://api.example.com"}},
+		ResourceGroups: []api.ResourceGroup{
+			{
+				Resources: []*api.Resource{
+					{
+						Transitions: []*api.Transition{
+							tr("Files", "https://api.example.com/files{/path*}", nil, 200, `<% .Param "path" %>`),
+							tr("Raw", "https://api.example.com/raw/{+path}", nil, 200, `raw <% .Param "path" %>`),
+							tr("Report", "https://api.example.com/reports/{id}{.format}", nil, 200, `<% .Param "id" %> as <% .Param "format" %>`),
+							tr("Search", "https://api.example.com/search{?q,page}", []api.Parameter{{Key: "q", Required: true}}, 200, "all"),
+							tr("Search Done", "https://api.example.com/search{?q,status}", []api.Parameter{{Key: "q", Required: true}, {Key: "status", Value: "done"}}, 200, "done"),
+							tr("Note", "https://api.example.com/notes/{id}{#section}", nil, 200, `note <% .Param "id" %>`),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	h := snowboard.MockHandler(snowboard.Mock(b))
+
+	cases := []struct {
+		target string
+		code   int
+		body   string
+	}{
+		{"/files/a/b%20c", 200, "a/b c"},
+		{"/files/a%2525b", 200, "a%25b"},
+		{"/files", 200, ""},
+		{"/raw/x/y/z", 200, "raw x/y/z"},
+		{"/reports/7.csv", 200, "7 as csv"},
+		{"/search?q=go", 200, "all"},
+		{"/search?q=go&status=done", 200, "done"},
+		{"/search?status=done", 400, "Missing required query parameter: q\n"},
+		{"/notes/3", 200, "note 3"},
+		{"/unknown/3", 404, ""},
+	}
+
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", c.target, nil))
+		assert.Equal(t, c.code, w.Code, c.target)
+		assert.Equal(t, c.body, w.Body.String(), c.target)
+	}
+}
+
+func TestMockServer_cors(t *testing.T) {
+	s, err := snowboard.NewMockServer(mockFixture(), &snowboard.MockConfig{
+		CORS: &snowboard.MockCORS{Origins: []string{"http://localhost:*"}, MaxAge: 600},
+	})
+	assert.Nil(t, err)
+
+	r := httptest.NewRequest("OPTIONS", "/notes", nil)
+	r.Header.Set("Origin", "http://localhost:3000")
+	r.Header.Set("Access-Control-Request-Method", "POST")
+	r.Header.Set("Access-Control-Request-Headers", "content-type")
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	assert.Equal(t, 204, w.Code)
+	assert.Equal(t, "http://localhost:3000", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "OPTIONS, POST", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "content-type", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+
+	r = httptest.NewRequest("GET", "/notes/1", nil)
+	r.Header.Set("Origin", "http://localhost:3000")
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "http://localhost:3000", w.Header().Get("Access-Control-Allow-Origin"))
+
+	r = httptest.NewRequest("GET", "/notes/1", nil)
+	r.Header.Set("Origin", "https://example.com")
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	assert.Equal(t, "", w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestMockServer_corsCredentials(t *testing.T) {
+	origin := func(c *snowboard.MockCORS, origin string) http.Header {
+		s, err := snowboard.NewMockServer(mockFixture(), &snowboard.MockConfig{CORS: c})
+		assert.Nil(t, err)
+
+		r := httptest.NewRequest("GET", "/notes/1", nil)
+		r.Header.Set("Origin", origin)
+
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+
+		return w.Header()
+	}
+
+	h := origin(&snowboard.MockCORS{Origins: []string{"http://localhost:3000"}, Credentials: true}, "http://localhost:3000")
+	assert.Equal(t, "http://localhost:3000", h.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", h.Get("Access-Control-Allow-Credentials"))
+
+	h = origin(&snowboard.MockCORS{Origins: []string{"*"}}, "https://app.example.com")
+	assert.Equal(t, "*", h.Get("Access-Control-Allow-Origin"))
+
+	c := &snowboard.MockCORS{Origins: []string{"https://*.example.com"}, Credentials: true}
+	assert.Equal(t, "https://app.example.com", origin(c, "https://app.example.com").Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "", origin(c, "https://example.org").Get("Access-Control-Allow-Origin"))
+
+	for _, c := range []*snowboard.MockCORS{
+		{Credentials: true},
+		{Origins: []string{"https://app.example.com", "*"}, Credentials: true},
+	} {
+		_, err := snowboard.NewMockServer(mockFixture(), &snowboard.MockConfig{CORS: c})
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "Invalid CORS")
+		}
+	}
+}
+
+func TestMockServer_headers(t *testing.T) {
+	ms := mockFixture()
+	ms[0].Headers = []api.Header{
+		{Key: "Location", Value: `/notes/<% .Field "id" %>`},
+		{Key: "Link", Value: `</notes?page=2>; rel="next"`},
+		{Key: "Link", Value: `</notes?page=9>; rel="last"`},
+	}
+	ms[1].Headers = []api.Header{{Key: "ETag", Value: `"note-{id}"`}, {Key: "Content-Length", Value: "99"}}
+
+	s, err := snowboard.NewMockServer(ms, &snowboard.MockConfig{
+		Routes: []snowboard.MockRoute{{Method: "GET", Path: "/notes/{id}", Headers: map[string]string{"x-version": "2"}}},
+	})
+	assert.Nil(t, err)
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("POST", "/notes", strings.NewReader(`{"id": 5}`)))
+	assert.Equal(t, "/notes/5", w.Header().Get("Location"))
+	assert.Len(t, w.Header()["Link"], 2)
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("GET", "/notes/42", nil))
+	assert.Equal(t, `"note-42"`, w.Header().Get("ETag"))
+	assert.Equal(t, "2", w.Header().Get("X-Version"))
+	assert.Equal(t, "", w.Header().Get("Content-Length"))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+}
+
+func TestMockServer_scenario(t *testing.T) {
+	c, err := snowboard.LoadMockConfig("../fixtures/mock/jobs.toml")
+	assert.Nil(t, err)
+
+	ms := []*snowboard.MockTransaction{
+		{Path: "/jobs", Pattern: "/jobs", Method: "POST", StatusCode: 202, Body: "created"},
+		{Path: "/jobs/:id", Pattern: "/jobs/:id", Method: "GET", Title: "Pending", StatusCode: 200, Body: "pending"},
+		{Path: "/jobs/:id", Pattern: "/jobs/:id", Method: "GET", Title: "Done", StatusCode: 200, Body: "done"},
+	}
+
+	s, err := snowboard.NewMockServer(ms, c)
+	assert.Nil(t, err)
+
+	do := func(method, target, session string) string {
+		r := httptest.NewRequest(method, target, nil)
+		if session != "" {
+			r.Header.Set("X-Mock-Scenario", "job")
+			r.Header.Set("X-Mock-Session", session)
+		}
+
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		return w.Body.String()
+	}
+
+	assert.Equal(t, "created", do("POST", "/jobs", "a"))
+	assert.Equal(t, "pending", do("GET", "/jobs/1", "a"))
+	assert.Equal(t, "created", do("POST", "/jobs", "b"))
+	assert.Equal(t, "pending", do("GET", "/jobs/1", "a"))
+	assert.Equal(t, "done", do("GET", "/jobs/1", "a"))
+	assert.Equal(t, "done", do("GET", "/jobs/1", "a"))
+	assert.Equal(t, "pending", do("GET", "/jobs/1", "b"))
+
+	// without scenario the later response wins
+	assert.Equal(t, "done", do("GET", "/jobs/1", ""))
+
+	assert.Nil(t, s.Scenario("", "job"))
+	assert.Equal(t, "pending", do("GET", "/jobs/1", ""))
+	assert.NotNil(t, s.Scenario("", "unknown"))
+
+	c.Scenarios[0].Steps[2].Title = "Failed"
+	_, err = snowboard.NewMockServer(ms, c)
+	assert.NotNil(t, err)
+}
+
+func TestMockServer_journal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	name := dir + "/mock.jsonl"
+
+	j, err := snowboard.OpenMockJournal(name, 1000, 1)
+	assert.Nil(t, err)
+
+	s, err := snowboard.NewMockServer(mockFixture(), nil)
+	assert.Nil(t, err)
+	s.SetJournal(j)
+
+	for _, body := range []string{`{"title": "a"}`, `{"title":`, `{"title": "c"}`} {
+		r := httptest.NewRequest("POST", "http://example.com/notes", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		s.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "http://example.com/unknown?q=1", nil))
+	assert.Nil(t, j.Close())
+
+	f, err := os.Open(name + ".1")
+	assert.Nil(t, err)
+	rotated, err := snowboard.ReadMockJournal(f)
+	f.Close()
+	assert.Nil(t, err)
+
+	f, err = os.Open(name)
+	assert.Nil(t, err)
+	entries, err := snowboard.ReadMockJournal(f)
+	f.Close()
+	assert.Nil(t, err)
+
+	entries = append(rotated, entries...)
+	assert.True(t, len(rotated) > 0)
+	assert.Len(t, entries, 4)
+
+	assert.Equal(t, "http://example.com/notes", entries[0].URL)
+	assert.Equal(t, `{"title": "a"}`, entries[0].Request.Body)
+	assert.Equal(t, `{"id": 1}`, entries[0].Response.Body)
+	assert.Equal(t, "application/json", entries[0].Response.Headers.Get("Content-Type"))
+	assert.Empty(t, entries[0].Errors)
+	assert.Equal(t, []string{"invalid JSON request body"}, entries[1].Errors)
+	assert.Equal(t, []string{"undocumented route"}, entries[3].Errors)
+
+	b, err := snowboard.MockHAR(entries)
+	assert.Nil(t, err)
+
+	har := struct {
+		Log struct {
+			Entries []struct {
+				Request struct {
+					URL         string
+					QueryString []struct{ Name, Value string }
+				}
+				Response struct {
+					Status  int
+					Content struct{ Text string }
+				}
+			}
+		}
+	}{}
+
+	assert.Nil(t, json.Unmarshal(b, &har))
+	assert.Len(t, har.Log.Entries, 4)
+	assert.Equal(t, 201, har.Log.Entries[0].Response.Status)
+	assert.Equal(t, `{"id": 1}`, har.Log.Entries[0].Response.Content.Text)
+	assert.Equal(t, 404, har.Log.Entries[3].Response.Status)
+	assert.Equal(t, "q", har.Log.Entries[3].Request.QueryString[0].Name)
+}
+
+func TestMockServer_capture(t *testing.T) {
+	ms := mockFixture()
+	ms[0].Body = `{"length": <% len .Body %>}`
+
+	s, err := snowboard.NewMockServer(ms, nil)
+	assert.Nil(t, err)
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("POST", "/notes", strings.NewReader(strings.Repeat("a", 100<<10))))
+	assert.Equal(t, `{"length": 102400}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("GET", "/__snowboard/requests", nil))
+
+	entries := []snowboard.MockLogEntry{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &entries))
+	assert.Len(t, entries, 1)
+	assert.Len(t, entries[0].Request.Body, 64<<10)
+	assert.True(t, entries[0].Request.Truncated)
+
+	s.SetLogSize(0)
+	s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/notes/1", nil))
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("GET", "/__snowboard/requests", nil))
+	assert.Equal(t, "[]\n", w.Body.String())
+}
+
+func TestMockServer_journalPreflight(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	name := dir + "/mock.jsonl"
+
+	j, err := snowboard.OpenMockJournal(name, 1<<20, 1)
+	assert.Nil(t, err)
+
+	s, err := snowboard.NewMockServer(mockFixture(), &snowboard.MockConfig{CORS: &snowboard.MockCORS{}})
+	assert.Nil(t, err)
+	s.SetJournal(j)
+
+	r := httptest.NewRequest("OPTIONS", "/notes", nil)
+	r.Header.Set("Origin", "http://localhost:3000")
+	r.Header.Set("Access-Control-Request-Method", "POST")
+	s.ServeHTTP(httptest.NewRecorder(), r)
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("GET", "/__snowboard/requests", nil))
+	assert.Nil(t, j.Close())
+
+	listed := []snowboard.MockLogEntry{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Len(t, listed, 1)
+	assert.Equal(t, "OPTIONS", listed[0].Method)
+	assert.Equal(t, 204, listed[0].StatusCode)
+
+	f, err := os.Open(name)
+	assert.Nil(t, err)
+	entries, err := snowboard.ReadMockJournal(f)
+	f.Close()
+	assert.Nil(t, err)
+
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "OPTIONS, POST", entries[0].Response.Headers.Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "http://example.com/__snowboard/requests", entries[1].URL)
+	assert.Equal(t, 200, entries[1].StatusCode)
+
+	// neither of them is a spec gap
+	assert.Empty(t, snowboard.Coverage(verifyFixture(), entries).Gaps)
+}
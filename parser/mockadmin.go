@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// mockAdminPrefix is the reserved namespace of mock server admin API
+const mockAdminPrefix = "/__snowboard/"
+
+// MockRouteInfo describes a served mock transaction
+type MockRouteInfo struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Title       string `json:"title,omitempty"`
+	StatusCode  int    `json:"status"`
+	ContentType string `json:"content_type"`
+}
+
+type mockScenarioRequest struct {
+	Name    string `json:"name"`
+	Session string `json:"session"`
+}
+
+type mockForceRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Status int    `json:"status"`
+}
+
+// serveAdmin handles admin API:
+//
+//	GET  /__snowboard/routes    lists routes
+//	GET  /__snowboard/requests  lists recent requests
+//	GET  /__snowboard/har       exports recent requests as HAR
+//	POST /__snowboard/next      forces the next response of a route
+//	POST /__snowboard/scenario  activates scenario of a session
+//	POST /__snowboard/reset     clears requests, forced responses, rate limits and scenarios
+//	POST /__snowboard/reload    reloads blueprint
+func (s *MockServer) serveAdmin(w http.ResponseWriter, r *http.Request) {
+	action := strings.TrimPrefix(r.URL.Path, mockAdminPrefix)
+
+	method := http.MethodPost
+	if action == "routes" || action == "requests" || action == "har" {
+		method = http.MethodGet
+	}
+
+	switch action {
+	case "routes", "requests", "har", "next", "scenario", "reset", "reload":
+	default:
+		adminJSON(w, http.StatusNotFound, adminError("unknown admin endpoint"))
+		return
+	}
+
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		adminJSON(w, http.StatusMethodNotAllowed, adminError("method not allowed"))
+		return
+	}
+
+	switch action {
+	case "routes":
+		xs := []MockRouteInfo{}
+		for _, m := range s.Transactions() {
+			xs = append(xs, MockRouteInfo{Method: m.Method, Path: m.Pattern, Title: m.Title, StatusCode: m.StatusCode, ContentType: m.ContentType})
+		}
+
+		adminJSON(w, http.StatusOK, xs)
+	case "requests":
+		adminJSON(w, http.StatusOK, s.log.list())
+	case "har":
+		b, err := MockHAR(s.log.list())
+		if err != nil {
+			adminJSON(w, http.StatusInternalServerError, adminError(err.Error()))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+	case "next":
+		var x mockForceRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&x); err != nil {
+			adminJSON(w, http.StatusBadRequest, adminError("invalid body: "+err.Error()))
+			return
+		}
+
+		if x.Method == "" {
+			x.Method = http.MethodGet
+		}
+
+		if x.Path == "" || x.Status < 100 || x.Status > 599 {
+			adminJSON(w, http.StatusBadRequest, adminError("path and status are required"))
+			return
+		}
+
+		if !s.hasRoute(x.Method, x.Path) {
+			adminJSON(w, http.StatusNotFound, adminError("unknown route "+strings.ToUpper(x.Method)+" "+x.Path))
+			return
+		}
+
+		s.Force(x.Method, x.Path, x.Status)
+		adminJSON(w, http.StatusOK, x)
+	case "scenario":
+		var x mockScenarioRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&x); err != nil {
+			adminJSON(w, http.StatusBadRequest, adminError("invalid body: "+err.Error()))
+			return
+		}
+
+		if err := s.Scenario(x.Session, x.Name); err != nil {
+			adminJSON(w, http.StatusNotFound, adminError(err.Error()))
+			return
+		}
+
+		adminJSON(w, http.StatusOK, x)
+	case "reset":
+		s.Reset()
+		adminJSON(w, http.StatusOK, map[string]bool{"ok": true})
+	case "reload":
+		if err := s.Reload(); err != nil {
+			adminJSON(w, http.StatusUnprocessableEntity, adminError(err.Error()))
+			return
+		}
+
+		adminJSON(w, http.StatusOK, map[string]bool{"ok": true})
+	}
+}
+
+func (s *MockServer) hasRoute(method, pattern string) bool {
+	p := routeKey(pattern, "")
+
+	for _, m := range s.Transactions() {
+		if strings.EqualFold(m.Method, method) && m.Path == p {
+			return true
+		}
+	}
+
+	return false
+}
+
+func adminError(msg string) map[string]string {
+	return map[string]string{"error": msg}
+}
+
+func adminJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
@@ -0,0 +1,277 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MockChaos describes faults injected into mock responses. Empty Method and
+// Path match every route, Path may contain "*" wildcard. Rates are
+// probabilities between 0 and 1.
+type MockChaos struct {
+	Method       string  `toml:"method"`
+	Path         string  `toml:"path"`
+	Latency      string  `toml:"latency"`
+	FailureRate  float64 `toml:"failure_rate"`
+	DropRate     float64 `toml:"drop_rate"`
+	TruncateRate float64 `toml:"truncate_rate"`
+	RateLimit    int     `toml:"rate_limit"`
+	RateWindow   string  `toml:"rate_window"`
+}
+
+// Request headers overriding chaos of a single request
+const (
+	mockDelayHeader        = "X-Mock-Delay"
+	mockFailureRateHeader  = "X-Mock-Failure-Rate"
+	mockDropRateHeader     = "X-Mock-Drop-Rate"
+	mockTruncateRateHeader = "X-Mock-Truncate-Rate"
+)
+
+// chaos is resolved MockChaos of a request
+type chaos struct {
+	minDelay     time.Duration
+	maxDelay     time.Duration
+	failureRate  float64
+	dropRate     float64
+	truncateRate float64
+	rateLimit    int
+	rateWindow   time.Duration
+}
+
+// Validate reports invalid durations or rates
+func (c MockChaos) Validate() error {
+	if _, _, err := parseLatency(c.Latency); err != nil {
+		return err
+	}
+
+	if c.RateWindow != "" {
+		if _, err := time.ParseDuration(c.RateWindow); err != nil {
+			return fmt.Errorf("Invalid rate window %q", c.RateWindow)
+		}
+	}
+
+	for _, r := range []float64{c.FailureRate, c.DropRate, c.TruncateRate} {
+		if r < 0 || r > 1 {
+			return fmt.Errorf("Invalid rate %v, it should be between 0 and 1", r)
+		}
+	}
+
+	if c.RateLimit < 0 {
+		return fmt.Errorf("Invalid rate limit %d", c.RateLimit)
+	}
+
+	return nil
+}
+
+func (c MockChaos) match(method, pattern string) bool {
+	if c.Method != "" && !strings.EqualFold(c.Method, method) {
+		return false
+	}
+
+	if c.Path == "" {
+		return true
+	}
+
+	ok, err := path.Match(routeKey(c.Path, ""), pattern)
+	return err == nil && ok
+}
+
+// parseLatency parses fixed latency such as "200ms", or random latency
+// within range such as "100ms-2s". Plain numbers are milliseconds.
+func parseLatency(s string) (time.Duration, time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, 0, nil
+	}
+
+	xs := strings.SplitN(s, "-", 2)
+	ds := []time.Duration{}
+
+	for _, x := range xs {
+		x = strings.TrimSpace(x)
+
+		if n, err := strconv.Atoi(x); err == nil {
+			x = strconv.Itoa(n) + "ms"
+		}
+
+		d, err := time.ParseDuration(x)
+		if err != nil || d < 0 {
+			return 0, 0, fmt.Errorf("Invalid latency %q", s)
+		}
+
+		ds = append(ds, d)
+	}
+
+	if len(ds) == 1 {
+		return ds[0], ds[0], nil
+	}
+
+	if ds[1] < ds[0] {
+		return 0, 0, fmt.Errorf("Invalid latency %q", s)
+	}
+
+	return ds[0], ds[1], nil
+}
+
+// resolveChaos merges chaos entries matching the route, later entries
+// override earlier ones, then request headers override them all
+func resolveChaos(cs []MockChaos, method, pattern string, h http.Header) chaos {
+	x := chaos{rateWindow: time.Second}
+
+	for _, c := range cs {
+		if !c.match(method, pattern) {
+			continue
+		}
+
+		if c.Latency != "" {
+			x.minDelay, x.maxDelay, _ = parseLatency(c.Latency)
+		}
+
+		if c.FailureRate != 0 {
+			x.failureRate = c.FailureRate
+		}
+
+		if c.DropRate != 0 {
+			x.dropRate = c.DropRate
+		}
+
+		if c.TruncateRate != 0 {
+			x.truncateRate = c.TruncateRate
+		}
+
+		if c.RateLimit != 0 {
+			x.rateLimit = c.RateLimit
+		}
+
+		if d, err := time.ParseDuration(c.RateWindow); err == nil && d > 0 {
+			x.rateWindow = d
+		}
+	}
+
+	if v := h.Get(mockDelayHeader); v != "" {
+		if min, max, err := parseLatency(v); err == nil {
+			x.minDelay, x.maxDelay = min, max
+		}
+	}
+
+	headerRate(h, mockFailureRateHeader, &x.failureRate)
+	headerRate(h, mockDropRateHeader, &x.dropRate)
+	headerRate(h, mockTruncateRateHeader, &x.truncateRate)
+
+	return x
+}
+
+func headerRate(h http.Header, key string, rate *float64) {
+	v := h.Get(key)
+	if v == "" {
+		return
+	}
+
+	if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 && f <= 1 {
+		*rate = f
+	}
+}
+
+func (x chaos) delay() time.Duration {
+	if x.maxDelay <= x.minDelay {
+		return x.minDelay
+	}
+
+	return x.minDelay + time.Duration(randomInt(int(x.maxDelay-x.minDelay)+1))
+}
+
+// chance returns true with probability rate
+func chance(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+
+	if rate >= 1 {
+		return true
+	}
+
+	return float64(randomInt(1000000)) < rate*1000000
+}
+
+// sleep waits for d or until request is cancelled
+func sleep(r *http.Request, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+	case <-r.Context().Done():
+	}
+}
+
+// dropConnection closes client connection without writing response
+func dropConnection(w http.ResponseWriter) {
+	if hj, ok := w.(http.Hijacker); ok {
+		if conn, _, err := hj.Hijack(); err == nil {
+			conn.Close()
+			return
+		}
+	}
+
+	panic(http.ErrAbortHandler)
+}
+
+// rateLimiter counts requests of each route within fixed windows
+type rateLimiter struct {
+	mu      sync.Mutex
+	windows map[string]*rateWindow
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{windows: map[string]*rateWindow{}}
+}
+
+// allow counts request of key, when limit is exceeded it returns false along
+// with number of seconds until the window resets
+func (l *rateLimiter) allow(key string, limit int, window time.Duration) (bool, int) {
+	if limit <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	w, ok := l.windows[key]
+
+	if !ok || now.Sub(w.start) >= window {
+		w = &rateWindow{start: now}
+		l.windows[key] = w
+	}
+
+	w.count++
+
+	if w.count <= limit {
+		return true, 0
+	}
+
+	retry := w.start.Add(window).Sub(now).Seconds()
+	return false, int(math.Max(1, math.Ceil(retry)))
+}
+
+func (l *rateLimiter) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.windows = map[string]*rateWindow{}
+}
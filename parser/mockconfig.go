@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/subosito/snowboard/api"
+)
+
+// MockConfig is a side-car configuration of mock server, written in TOML
+type MockConfig struct {
+	Routes    []MockRoute    `toml:"route"`
+	Chaos     []MockChaos    `toml:"chaos"`
+	CORS      *MockCORS      `toml:"cors"`
+	Scenarios []MockScenario `toml:"scenario"`
+}
+
+// MockRoute overrides responses of documented route, or adds a new one when
+// the route is not documented. Path accepts both "/notes/{id}" and
+// "/notes/:id" forms. Zero Status overrides every response of the route.
+// Headers replace documented headers of the same name.
+type MockRoute struct {
+	Method      string            `toml:"method"`
+	Path        string            `toml:"path"`
+	Status      int               `toml:"status"`
+	ContentType string            `toml:"content_type"`
+	Headers     map[string]string `toml:"headers"`
+	Body        string            `toml:"body"`
+	File        string            `toml:"file"`
+}
+
+// LoadMockConfig reads mock configuration file. Body files of routes are
+// resolved relative to the configuration file.
+func LoadMockConfig(name string) (*MockConfig, error) {
+	c := &MockConfig{}
+
+	if _, err := toml.DecodeFile(name, c); err != nil {
+		return nil, fmt.Errorf("Unable to read mock config %s: %s", name, err)
+	}
+
+	for i, r := range c.Routes {
+		if r.Path == "" {
+			return nil, fmt.Errorf("Unable to read mock config %s: route #%d has no path", name, i+1)
+		}
+
+		if r.File == "" {
+			continue
+		}
+
+		b, err := ioutil.ReadFile(filepath.Join(filepath.Dir(name), r.File))
+		if err != nil {
+			return nil, err
+		}
+
+		c.Routes[i].Body = string(b)
+	}
+
+	return c, nil
+}
+
+// Apply returns mock transactions overridden by configured routes
+func (c *MockConfig) Apply(ms []*MockTransaction) []*MockTransaction {
+	for _, r := range c.Routes {
+		method := strings.ToUpper(r.Method)
+		if method == "" {
+			method = "GET"
+		}
+
+		key := routeKey(r.Path, "")
+		found := false
+
+		for _, m := range ms {
+			if m.Method != method || m.Path != key {
+				continue
+			}
+
+			if r.Status != 0 && m.StatusCode != r.Status {
+				continue
+			}
+
+			found = true
+
+			if r.ContentType != "" {
+				m.ContentType = r.ContentType
+			}
+
+			if r.Body != "" || r.File != "" {
+				m.Body = r.Body
+			}
+
+			m.Headers = overrideHeaders(m.Headers, r.Headers)
+		}
+
+		if found {
+			continue
+		}
+
+		status := r.Status
+		if status == 0 {
+			status = 200
+		}
+
+		ms = append(ms, &MockTransaction{
+			Path:        key,
+			Pattern:     transformURL(r.Path, ""),
+			Query:       mockQuery(r.Path, nil),
+			Method:      method,
+			StatusCode:  status,
+			ContentType: r.ContentType,
+			Body:        r.Body,
+			Headers:     overrideHeaders(nil, r.Headers),
+		})
+	}
+
+	return ms
+}
+
+// overrideHeaders returns hs where headers of the same name are replaced by
+// values of xs
+func overrideHeaders(hs []api.Header, xs map[string]string) []api.Header {
+	if len(xs) == 0 {
+		return hs
+	}
+
+	replaced := map[string]bool{}
+	for k := range xs {
+		replaced[http.CanonicalHeaderKey(k)] = true
+	}
+
+	ys := []api.Header{}
+
+	for _, h := range hs {
+		if !replaced[http.CanonicalHeaderKey(h.Key)] {
+			ys = append(ys, h)
+		}
+	}
+
+	keys := []string{}
+	for k := range xs {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		ys = append(ys, api.Header{Key: k, Value: xs[k]})
+	}
+
+	return ys
+}
@@ -0,0 +1,170 @@
+package parser
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// MockCORS configures CORS headers of mock server. Origins may contain "*"
+// wildcard, e.g. "http://localhost:*" or "https://*.example.com", and
+// matching origin is echoed back. Empty Origins allow any origin. Empty
+// Methods allow methods of the requested route, and empty Headers allow
+// headers requested by preflight.
+type MockCORS struct {
+	Origins       []string `toml:"origins"`
+	Methods       []string `toml:"methods"`
+	Headers       []string `toml:"headers"`
+	ExposeHeaders []string `toml:"expose_headers"`
+	Credentials   bool     `toml:"credentials"`
+	MaxAge        int      `toml:"max_age"`
+}
+
+// Validate returns error when credentials are allowed for any origin, which
+// would let every site make authenticated requests
+func (c *MockCORS) Validate() error {
+	if !c.Credentials {
+		return nil
+	}
+
+	if len(c.Origins) == 0 {
+		return fmt.Errorf("Invalid CORS: credentials need explicit origins")
+	}
+
+	for _, o := range c.Origins {
+		if o == "*" {
+			return fmt.Errorf("Invalid CORS: credentials can't be allowed for any origin")
+		}
+	}
+
+	return nil
+}
+
+// allowOrigin returns value of Access-Control-Allow-Origin for origin, or
+// empty string when origin is not allowed
+func (c *MockCORS) allowOrigin(origin string) string {
+	origins := c.Origins
+	if len(origins) == 0 {
+		origins = []string{"*"}
+	}
+
+	for _, o := range origins {
+		if o == "*" {
+			return "*"
+		}
+
+		if matchOrigin(o, origin) {
+			return origin
+		}
+	}
+
+	return ""
+}
+
+// matchOrigin reports whether origin matches pattern, where "*" stands for
+// any run of characters
+func matchOrigin(pattern, origin string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == origin
+	}
+
+	if !strings.HasPrefix(origin, parts[0]) {
+		return false
+	}
+
+	origin = origin[len(parts[0]):]
+
+	for _, p := range parts[1 : len(parts)-1] {
+		i := strings.Index(origin, p)
+		if i == -1 {
+			return false
+		}
+
+		origin = origin[i+len(p):]
+	}
+
+	return strings.HasSuffix(origin, parts[len(parts)-1])
+}
+
+// writeHeaders adds CORS headers of actual request, it reports whether origin
+// is allowed
+func (c *MockCORS) writeHeaders(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+
+	h := w.Header()
+	h.Add("Vary", "Origin")
+
+	allowed := c.allowOrigin(origin)
+	if allowed == "" {
+		return false
+	}
+
+	h.Set("Access-Control-Allow-Origin", allowed)
+
+	if c.Credentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if len(c.ExposeHeaders) > 0 {
+		h.Set("Access-Control-Expose-Headers", strings.Join(c.ExposeHeaders, ", "))
+	}
+
+	return true
+}
+
+// isPreflight reports whether r is CORS preflight request
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Origin") != "" && r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// preflight answers CORS preflight request of route allowing methods allow
+func (c *MockCORS) preflight(w http.ResponseWriter, r *http.Request, allow []string) {
+	if len(allow) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	h := w.Header()
+	h.Add("Vary", "Access-Control-Request-Method")
+	h.Add("Vary", "Access-Control-Request-Headers")
+
+	methods := c.Methods
+	if len(methods) == 0 {
+		methods = allow
+	}
+
+	method := r.Header.Get("Access-Control-Request-Method")
+	if !c.writeHeaders(w, r) || !containsFoldAny(methods, method) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	h.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+
+	if len(c.Headers) > 0 {
+		h.Set("Access-Control-Allow-Headers", strings.Join(c.Headers, ", "))
+	} else if s := r.Header.Get("Access-Control-Request-Headers"); s != "" {
+		h.Set("Access-Control-Allow-Headers", s)
+	}
+
+	if c.MaxAge > 0 {
+		h.Set("Access-Control-Max-Age", strconv.Itoa(c.MaxAge))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func containsFoldAny(xs []string, s string) bool {
+	for _, x := range xs {
+		if strings.EqualFold(x, s) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,211 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MockJournal appends served requests to a JSON lines file, rotating it when
+// it grows beyond maxSize bytes. Rotated files are suffixed by ".1", ".2" and
+// so on, files beyond backups are removed.
+type MockJournal struct {
+	mu      sync.Mutex
+	name    string
+	maxSize int64
+	backups int
+	f       *os.File
+	size    int64
+}
+
+// OpenMockJournal opens journal file name for appending
+func OpenMockJournal(name string, maxSize int64, backups int) (*MockJournal, error) {
+	j := &MockJournal{name: name, maxSize: maxSize, backups: backups}
+
+	if err := j.open(); err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+func (j *MockJournal) open() error {
+	f, err := os.OpenFile(j.name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("Unable to open mock journal %s: %s", j.name, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	j.f = f
+	j.size = info.Size()
+
+	return nil
+}
+
+// Write appends entry e as a single line
+func (j *MockJournal) Write(e *MockLogEntry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	b = append(b, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.maxSize > 0 && j.size > 0 && j.size+int64(len(b)) > j.maxSize {
+		if err := j.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := j.f.Write(b)
+	j.size += int64(n)
+
+	return err
+}
+
+func (j *MockJournal) rotate() error {
+	if err := j.f.Close(); err != nil {
+		return err
+	}
+
+	if j.backups <= 0 {
+		if err := os.Remove(j.name); err != nil {
+			return err
+		}
+
+		return j.open()
+	}
+
+	for i := j.backups - 1; i >= 0; i-- {
+		src := j.name
+		if i > 0 {
+			src += "." + strconv.Itoa(i)
+		}
+
+		err := os.Rename(src, j.name+"."+strconv.Itoa(i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return j.open()
+}
+
+// Close closes journal file
+func (j *MockJournal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.f.Close()
+}
+
+// ReadMockJournal reads entries written by MockJournal
+func ReadMockJournal(r io.Reader) ([]*MockLogEntry, error) {
+	xs := []*MockLogEntry{}
+	dec := json.NewDecoder(r)
+
+	for {
+		e := &MockLogEntry{}
+
+		err := dec.Decode(e)
+		if err == io.EOF {
+			return xs, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read mock journal: %s", err)
+		}
+
+		xs = append(xs, e)
+	}
+}
+
+// MockHAR returns entries as HTTP Archive, so that traffic can be inspected
+// by browser tools or replayed
+func MockHAR(entries []*MockLogEntry) ([]byte, error) {
+	h := harLog{
+		Version: "1.2",
+		Creator: harCreator{Name: "snowboard"},
+		Entries: []harEntry{},
+	}
+
+	for _, e := range entries {
+		h.Entries = append(h.Entries, mockHAREntry(e))
+	}
+
+	return json.MarshalIndent(map[string]harLog{"log": h}, "", "  ")
+}
+
+func mockHAREntry(e *MockLogEntry) harEntry {
+	proto := e.Proto
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+
+	d, _ := time.ParseDuration(e.Duration)
+	ms := float64(d) / float64(time.Millisecond)
+
+	x := harEntry{
+		StartedDateTime: e.Time.Format(time.RFC3339Nano),
+		Time:            ms,
+		Request: harRequest{
+			Method:      e.Method,
+			URL:         e.URL,
+			HTTPVersion: proto,
+			Cookies:     []harHeader{},
+			Headers:     []harHeader{},
+			QueryString: harQueryString(e.URL),
+			HeadersSize: -1,
+		},
+		Response: harResponse{
+			Status:      e.StatusCode,
+			StatusText:  http.StatusText(e.StatusCode),
+			HTTPVersion: proto,
+			Cookies:     []harHeader{},
+			Headers:     []harHeader{},
+			HeadersSize: -1,
+		},
+		Cache:   map[string]interface{}{},
+		Timings: harTimings{Wait: ms},
+	}
+
+	if q := e.Request; q != nil {
+		x.Request.Headers = harHeaders(q.Headers)
+		x.Request.BodySize = len(q.Body)
+
+		if q.Body != "" {
+			x.Request.PostData = &harPostData{MimeType: q.Headers.Get("Content-Type"), Text: q.Body}
+		}
+	}
+
+	if p := e.Response; p != nil {
+		x.Response.Headers = harHeaders(p.Headers)
+		x.Response.BodySize = len(p.Body)
+		x.Response.RedirectURL = p.Headers.Get("Location")
+		x.Response.Content = harContent{Size: len(p.Body), MimeType: p.Headers.Get("Content-Type"), Text: p.Body}
+	}
+
+	switch {
+	case e.Dropped:
+		x.Comment = "connection dropped"
+	case e.Scenario != "":
+		x.Comment = "scenario " + e.Scenario
+	case e.Forced:
+		x.Comment = "forced response"
+	}
+
+	return x
+}
@@ -0,0 +1,187 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// mockLogSize is the number of recent requests kept by mock server
+const mockLogSize = 100
+
+// mockBodyLimit is the number of body bytes kept of each request and response
+const mockBodyLimit = 64 << 10
+
+// MockLogEntry is a request served by mock server along with the matched
+// route, written response and problems found in the request
+type MockLogEntry struct {
+	Time       time.Time    `json:"time"`
+	Method     string       `json:"method"`
+	URL        string       `json:"url"`
+	Proto      string       `json:"proto,omitempty"`
+	Route      string       `json:"route"`
+	StatusCode int          `json:"status"`
+	Duration   string       `json:"duration"`
+	Forced     bool         `json:"forced,omitempty"`
+	Scenario   string       `json:"scenario,omitempty"`
+	Dropped    bool         `json:"dropped,omitempty"`
+	Errors     []string     `json:"errors,omitempty"`
+	Request    *MockMessage `json:"request,omitempty"`
+	Response   *MockMessage `json:"response,omitempty"`
+}
+
+// MockMessage is headers and body of logged request or response. Bodies are
+// cut at 64 KiB.
+type MockMessage struct {
+	Headers   http.Header `json:"headers"`
+	Body      string      `json:"body,omitempty"`
+	Truncated bool        `json:"truncated,omitempty"`
+}
+
+func newMockMessage(h http.Header, body []byte) *MockMessage {
+	m := &MockMessage{Headers: cloneHeader(h)}
+
+	if len(body) > mockBodyLimit {
+		body = body[:mockBodyLimit]
+		m.Truncated = true
+	}
+
+	m.Body = string(body)
+	return m
+}
+
+// captureRequest records request r into e, reading no more of its body than
+// is kept. Handler reads the captured bytes followed by the rest of body.
+func captureRequest(r *http.Request, e *MockLogEntry) {
+	var b []byte
+
+	if r.Body != nil {
+		b, _ = ioutil.ReadAll(io.LimitReader(r.Body, mockBodyLimit+1))
+		r.Body = replayBody{Reader: io.MultiReader(bytes.NewReader(b), r.Body), Closer: r.Body}
+	}
+
+	e.Request = newMockMessage(r.Header, b)
+}
+
+// replayBody is request body whose beginning was read already
+type replayBody struct {
+	io.Reader
+	io.Closer
+}
+
+func cloneHeader(h http.Header) http.Header {
+	x := http.Header{}
+
+	for k, vs := range h {
+		x[k] = append([]string{}, vs...)
+	}
+
+	return x
+}
+
+// mockLog keeps the most recent requests in a ring buffer
+type mockLog struct {
+	mu      sync.Mutex
+	size    int
+	entries []*MockLogEntry
+}
+
+func newMockLog(size int) *mockLog {
+	return &mockLog{size: size}
+}
+
+func (l *mockLog) add(e *MockLogEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = append(l.entries, e)
+
+	if len(l.entries) > l.size {
+		l.entries = l.entries[len(l.entries)-l.size:]
+	}
+}
+
+func (l *mockLog) resize(size int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if size < 0 {
+		size = 0
+	}
+
+	l.size = size
+
+	if len(l.entries) > l.size {
+		l.entries = l.entries[len(l.entries)-l.size:]
+	}
+}
+
+func (l *mockLog) list() []*MockLogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]*MockLogEntry{}, l.entries...)
+}
+
+func (l *mockLog) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = nil
+}
+
+// mockResponseWriter records status code, headers and body written by
+// handler
+type mockResponseWriter struct {
+	http.ResponseWriter
+	status int
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (w *mockResponseWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+		w.header = cloneHeader(w.Header())
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *mockResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if n := mockBodyLimit + 1 - w.body.Len(); n > 0 {
+		if len(b) < n {
+			n = len(b)
+		}
+
+		w.body.Write(b[:n])
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// message returns written response, or nil when nothing was written
+func (w *mockResponseWriter) message() *MockMessage {
+	if w.status == 0 {
+		return nil
+	}
+
+	return newMockMessage(w.header, w.body.Bytes())
+}
+
+func (w *mockResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hj, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return hj.Hijack()
+	}
+
+	return nil, nil, http.ErrNotSupported
+}
@@ -0,0 +1,210 @@
+package parser
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// MockScenario is a named sequence of responses, e.g. a job which is pending
+// for a while before it is done. Clients select scenario by X-Mock-Scenario
+// header or mock_scenario cookie, and keep separate progress by
+// X-Mock-Session header or mock_session cookie.
+type MockScenario struct {
+	Name  string     `toml:"name"`
+	Steps []MockStep `toml:"step"`
+}
+
+// MockStep responds to a route with response of Status or request Title,
+// Repeat times before moving on to the next step. Requests of routes not
+// found in the remaining steps are served as usual, and the last step repeats
+// forever.
+type MockStep struct {
+	Method string `toml:"method"`
+	Path   string `toml:"path"`
+	Status int    `toml:"status"`
+	Title  string `toml:"title"`
+	Repeat int    `toml:"repeat"`
+}
+
+// Request headers and cookies selecting scenario of a client
+const (
+	mockScenarioHeader = "X-Mock-Scenario"
+	mockScenarioCookie = "mock_scenario"
+	mockSessionHeader  = "X-Mock-Session"
+	mockSessionCookie  = "mock_session"
+)
+
+// Validate reports steps without route or without response among ms
+func (x MockScenario) Validate(ms []*MockTransaction) error {
+	if x.Name == "" {
+		return fmt.Errorf("Invalid scenario: name is required")
+	}
+
+	if len(x.Steps) == 0 {
+		return fmt.Errorf("Invalid scenario %s: no steps", x.Name)
+	}
+
+	for i, st := range x.Steps {
+		if st.Path == "" || st.Repeat < 0 {
+			return fmt.Errorf("Invalid scenario %s: step #%d needs path and non-negative repeat", x.Name, i+1)
+		}
+
+		if findTransaction(ms, func(m *MockTransaction) bool {
+			return st.match(m.Method, m.Path) && st.accept(m)
+		}) == nil {
+			return fmt.Errorf("Invalid scenario %s: step #%d matches no response", x.Name, i+1)
+		}
+	}
+
+	return nil
+}
+
+func (st MockStep) match(method, pattern string) bool {
+	m := strings.ToUpper(st.Method)
+	if m == "" {
+		m = http.MethodGet
+	}
+
+	return m == method && routeKey(st.Path, "") == pattern
+}
+
+// accept reports whether transaction m is response of the step
+func (st MockStep) accept(m *MockTransaction) bool {
+	if st.Status != 0 && m.StatusCode != st.Status {
+		return false
+	}
+
+	return st.Title == "" || strings.EqualFold(st.Title, m.Title)
+}
+
+// scenarioProgress is the current step of a scenario within a session
+type scenarioProgress struct {
+	step  int
+	count int
+}
+
+// advance returns the step serving route and moves the progress forward
+func (p *scenarioProgress) advance(x *MockScenario, method, pattern string) (MockStep, bool) {
+	for j := p.step; j < len(x.Steps); j++ {
+		st := x.Steps[j]
+		if !st.match(method, pattern) {
+			continue
+		}
+
+		if j != p.step {
+			p.step, p.count = j, 0
+		}
+
+		p.count++
+
+		repeat := st.Repeat
+		if repeat == 0 {
+			repeat = 1
+		}
+
+		if j < len(x.Steps)-1 && p.count >= repeat {
+			p.step, p.count = j+1, 0
+		}
+
+		return st, true
+	}
+
+	return MockStep{}, false
+}
+
+// scenarioSessions keeps progress of scenarios per session, along with
+// scenarios activated by admin API for sessions not selecting one
+type scenarioSessions struct {
+	mu       sync.Mutex
+	active   map[string]string
+	progress map[string]*scenarioProgress
+}
+
+func newScenarioSessions() *scenarioSessions {
+	return &scenarioSessions{
+		active:   map[string]string{},
+		progress: map[string]*scenarioProgress{},
+	}
+}
+
+// activate selects scenario name for session and restarts it, empty name
+// deactivates scenario of session
+func (ss *scenarioSessions) activate(session, name string) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if name == "" {
+		delete(ss.active, session)
+	} else {
+		ss.active[session] = name
+	}
+
+	delete(ss.progress, session+"\x00"+name)
+}
+
+// next returns transaction of the scenario step serving route, if any
+func (ss *scenarioSessions) next(cs []MockScenario, r *http.Request, m *mockRecord) (*MockTransaction, string) {
+	session := requestValue(r, mockSessionHeader, mockSessionCookie)
+	name := requestValue(r, mockScenarioHeader, mockScenarioCookie)
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if name == "" {
+		name = ss.active[session]
+	}
+
+	if name == "" {
+		return nil, ""
+	}
+
+	var x *MockScenario
+
+	for i := range cs {
+		if cs[i].Name == name {
+			x = &cs[i]
+		}
+	}
+
+	if x == nil {
+		return nil, ""
+	}
+
+	key := session + "\x00" + name
+
+	p, ok := ss.progress[key]
+	if !ok {
+		p = &scenarioProgress{}
+		ss.progress[key] = p
+	}
+
+	st, ok := p.advance(x, m.Method, m.Pattern)
+	if !ok {
+		return nil, ""
+	}
+
+	return findTransaction(m.Transactions, st.accept), name
+}
+
+func (ss *scenarioSessions) reset() {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ss.active = map[string]string{}
+	ss.progress = map[string]*scenarioProgress{}
+}
+
+// requestValue returns value of header, or cookie when header is absent
+func requestValue(r *http.Request, header, cookie string) string {
+	if v := r.Header.Get(header); v != "" {
+		return v
+	}
+
+	if c, err := r.Cookie(cookie); err == nil {
+		return c.Value
+	}
+
+	return ""
+}
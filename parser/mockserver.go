@@ -0,0 +1,503 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MockLoader loads transactions and configuration of mock server
+type MockLoader func() (MockTransactions, *MockConfig, error)
+
+// mockState is the immutable set of served transactions, swapped on reload
+type mockState struct {
+	transactions MockTransactions
+	config       *MockConfig
+	router       *mockRouter
+}
+
+// MockServer serves mock responses of blueprint transactions, customized by
+// mock configuration. Requests under /__snowboard/ are handled by admin API.
+type MockServer struct {
+	state     atomic.Value
+	load      MockLoader
+	limiter   *rateLimiter
+	log       *mockLog
+	scenarios *scenarioSessions
+	journal   *MockJournal
+
+	mu   sync.Mutex
+	next map[string][]int
+}
+
+// NewMockServer returns mock server of transactions. Routes of configuration
+// are applied to transactions and its chaos entries are validated.
+func NewMockServer(ms MockTransactions, c *MockConfig) (*MockServer, error) {
+	return LoadMockServer(func() (MockTransactions, *MockConfig, error) {
+		return ms, c, nil
+	})
+}
+
+// LoadMockServer returns mock server loaded by load. The loader is called
+// again on every reload.
+func LoadMockServer(load MockLoader) (*MockServer, error) {
+	s := &MockServer{
+		load:      load,
+		limiter:   newRateLimiter(),
+		log:       newMockLog(mockLogSize),
+		scenarios: newScenarioSessions(),
+		next:      map[string][]int{},
+	}
+
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Reload loads transactions and configuration again and swaps them in. On
+// failure the previous ones are kept.
+func (s *MockServer) Reload() error {
+	ms, c, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	if c == nil {
+		c = &MockConfig{}
+	}
+
+	for _, x := range c.Chaos {
+		if err := x.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.CORS != nil {
+		if err := c.CORS.Validate(); err != nil {
+			return err
+		}
+	}
+
+	ms = c.Apply(ms)
+
+	names := map[string]bool{}
+
+	for _, x := range c.Scenarios {
+		if err := x.Validate(ms); err != nil {
+			return err
+		}
+
+		if names[x.Name] {
+			return fmt.Errorf("Invalid scenario %s: duplicate name", x.Name)
+		}
+
+		names[x.Name] = true
+	}
+
+	s.state.Store(&mockState{
+		transactions: ms,
+		config:       c,
+		router:       ms.Router(),
+	})
+
+	return nil
+}
+
+// Reset clears request log, forced responses, rate limits and progress of
+// scenarios
+func (s *MockServer) Reset() {
+	s.mu.Lock()
+	s.next = map[string][]int{}
+	s.mu.Unlock()
+
+	s.limiter.reset()
+	s.log.reset()
+	s.scenarios.reset()
+}
+
+// Scenario activates scenario name from its first step for clients of
+// session which don't select a scenario themselves. Empty name deactivates
+// the scenario.
+func (s *MockServer) Scenario(session, name string) error {
+	if name != "" && !s.hasScenario(name) {
+		return fmt.Errorf("Unknown scenario %s", name)
+	}
+
+	s.scenarios.activate(session, name)
+	return nil
+}
+
+func (s *MockServer) hasScenario(name string) bool {
+	for _, x := range s.current().config.Scenarios {
+		if x.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Force makes the next request of route respond with status code. Forced
+// responses of the same route are queued.
+func (s *MockServer) Force(method, pattern string, status int) {
+	key := strings.ToUpper(method) + " " + routeKey(pattern, "")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.next[key] = append(s.next[key], status)
+}
+
+func (s *MockServer) forced(method, pattern string) int {
+	key := method + " " + pattern
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	xs := s.next[key]
+	if len(xs) == 0 {
+		return 0
+	}
+
+	if len(xs) == 1 {
+		delete(s.next, key)
+	} else {
+		s.next[key] = xs[1:]
+	}
+
+	return xs[0]
+}
+
+func (s *MockServer) current() *mockState {
+	return s.state.Load().(*mockState)
+}
+
+// SetLogSize sets the number of recent requests kept for admin API
+func (s *MockServer) SetLogSize(n int) {
+	s.log.resize(n)
+}
+
+// SetJournal makes mock server append every served request to journal j
+func (s *MockServer) SetJournal(j *MockJournal) {
+	s.journal = j
+}
+
+// Transactions returns served mock transactions
+func (s *MockServer) Transactions() MockTransactions {
+	return s.current().transactions
+}
+
+func (s *MockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	admin := strings.HasPrefix(r.URL.Path, mockAdminPrefix)
+
+	rw := &mockResponseWriter{ResponseWriter: w}
+	e := &MockLogEntry{Time: time.Now(), Method: r.Method, URL: requestURL(r), Proto: r.Proto}
+
+	captureRequest(r, e)
+
+	defer func() {
+		e.StatusCode = rw.status
+		e.Duration = time.Since(e.Time).String()
+		e.Response = rw.message()
+
+		// admin requests are journaled only, recent requests listed by admin
+		// API are requests of the mocked API
+		if !admin {
+			s.log.add(e)
+		}
+
+		if s.journal != nil {
+			if err := s.journal.Write(e); err != nil {
+				log.Printf("Unable to write mock journal: %s\n", err)
+			}
+		}
+	}()
+
+	if c := s.current().config.CORS; c != nil {
+		if isPreflight(r) {
+			allow := []string{http.MethodGet, http.MethodOptions, http.MethodPost}
+			if !admin {
+				allow = s.current().router.allow(r.URL.Path)
+			}
+
+			c.preflight(rw, r, allow)
+			return
+		}
+
+		c.writeHeaders(rw, r)
+	}
+
+	if admin {
+		s.serveAdmin(rw, r)
+		return
+	}
+
+	if alias(r.Header.Get("Content-Type")) == "json" && e.Request.Body != "" && !e.Request.Truncated && !json.Valid([]byte(e.Request.Body)) {
+		e.Errors = append(e.Errors, "invalid JSON request body")
+	}
+
+	s.serveMock(rw, r, e)
+}
+
+func (s *MockServer) serveMock(w http.ResponseWriter, r *http.Request, e *MockLogEntry) {
+	var n *MockTransaction
+
+	st := s.current()
+
+	m, params, found := st.router.lookup(r.Method, r.URL.Path)
+	if !found {
+		allow := st.router.allow(r.URL.Path)
+
+		switch {
+		case len(allow) == 0:
+			e.Errors = append(e.Errors, "undocumented route")
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodOptions:
+			w.Header().Set("Allow", strings.Join(allow, ", "))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			e.Errors = append(e.Errors, "undocumented method")
+			w.Header().Set("Allow", strings.Join(allow, ", "))
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+
+		return
+	}
+
+	e.Route = m.Pattern
+
+	x := resolveChaos(st.config.Chaos, m.Method, m.Pattern, r.Header)
+
+	sleep(r, x.delay())
+
+	if ok, retry := s.limiter.allow(m.Method+" "+m.Pattern, x.rateLimit, x.rateWindow); !ok {
+		log.Printf("%s\t%d\t%s\n", m.Method, http.StatusTooManyRequests, m.Pattern)
+
+		w.Header().Set("Retry-After", strconv.Itoa(retry))
+		n = findTransaction(m.Transactions, func(t *MockTransaction) bool {
+			return t.StatusCode == http.StatusTooManyRequests
+		})
+
+		if n == nil {
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			return
+		}
+	}
+
+	if n == nil && chance(x.dropRate) {
+		log.Printf("%s\tdrop\t%s\n", m.Method, m.Pattern)
+		e.Dropped = true
+		dropConnection(w)
+		return
+	}
+
+	q := r.URL.Query()
+	status := r.Header.Get("X-Status-Code")
+
+	if n == nil {
+		if code := s.forced(m.Method, m.Pattern); code != 0 {
+			e.Forced = true
+			status = strconv.Itoa(code)
+
+			n = findTransaction(m.Transactions, func(t *MockTransaction) bool {
+				return t.StatusCode == code
+			})
+
+			if n == nil {
+				log.Printf("%s\t%d\t%s\n", m.Method, code, m.Pattern)
+				http.Error(w, http.StatusText(code), code)
+				return
+			}
+		}
+	}
+
+	if n == nil && status == "" {
+		n, e.Scenario = s.scenarios.next(st.config.Scenarios, r, m)
+	}
+
+	if n == nil && status == "" {
+		if missing := missingQuery(m.Transactions, q); len(missing) > 0 {
+			msg := "Missing required query parameter: " + strings.Join(missing, ", ")
+			e.Errors = append(e.Errors, msg)
+
+			log.Printf("%s\t%d\t%s\n", m.Method, http.StatusBadRequest, m.Pattern)
+			http.Error(w, msg, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if n == nil && status == "" && chance(x.failureRate) {
+		n = randomTransaction(m.Transactions, func(t *MockTransaction) bool {
+			return t.StatusCode >= http.StatusInternalServerError
+		})
+
+		if n == nil {
+			log.Printf("%s\t%d\t%s\n", m.Method, http.StatusInternalServerError, m.Pattern)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if n == nil && status == "" {
+		n = selectTransaction(m.Transactions, q, func(t *MockTransaction) bool {
+			return t.StatusCode >= http.StatusOK && t.StatusCode < http.StatusBadRequest
+		})
+	} else if n == nil {
+		n = selectTransaction(m.Transactions, q, func(t *MockTransaction) bool {
+			return status == strconv.Itoa(t.StatusCode)
+		})
+	}
+
+	if n == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	log.Printf("%s\t%d\t%s\n", n.Method, n.StatusCode, n.Path)
+
+	body, err := n.Render(r, params)
+	if err == errMockRequestTooLarge {
+		log.Printf("%s\t%d\t%s\n", m.Method, http.StatusRequestEntityTooLarge, m.Pattern)
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if err != nil {
+		log.Printf("Unable to render mock response: %s\n", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if chance(x.truncateRate) && len(body) > 0 {
+		// declared length is longer than written body, so clients see
+		// unexpected EOF
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		body = body[:len(body)/2]
+	}
+
+	h, err := n.RenderHeaders(r, params)
+	if err == errMockRequestTooLarge {
+		log.Printf("%s\t%d\t%s\n", m.Method, http.StatusRequestEntityTooLarge, m.Pattern)
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if err != nil {
+		log.Printf("Unable to render mock response headers: %s\n", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for k, vs := range h {
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
+	}
+
+	if n.ContentType != "" || w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", n.ContentType)
+	}
+
+	if r.Method == http.MethodHead {
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(n.StatusCode)
+		return
+	}
+
+	w.WriteHeader(n.StatusCode)
+	io.WriteString(w, body)
+}
+
+// requestURL returns absolute URL of request r
+func requestURL(r *http.Request) string {
+	u := *r.URL
+	u.Host = r.Host
+
+	u.Scheme = "http"
+	if r.TLS != nil {
+		u.Scheme = "https"
+	}
+
+	return u.String()
+}
+
+func findTransaction(ts []*MockTransaction, fn func(*MockTransaction) bool) *MockTransaction {
+	for _, t := range ts {
+		if fn(t) {
+			return t
+		}
+	}
+
+	return nil
+}
+
+// selectTransaction returns transaction satisfying fn which fits query q best.
+// Transactions missing required query parameters are avoided, ties are won by
+// the later transaction.
+func selectTransaction(ts []*MockTransaction, q url.Values, fn func(*MockTransaction) bool) *MockTransaction {
+	var n *MockTransaction
+	best := -1
+
+	for _, t := range ts {
+		if !fn(t) {
+			continue
+		}
+
+		score := t.score(q)
+		if len(t.missing(q)) > 0 {
+			score = -1
+		}
+
+		if n == nil || score >= best {
+			n = t
+			best = score
+		}
+	}
+
+	return n
+}
+
+// missingQuery returns required query parameters absent from q, unless some
+// transaction has all of its required parameters
+func missingQuery(ts []*MockTransaction, q url.Values) []string {
+	var missing []string
+
+	for _, t := range ts {
+		xs := t.missing(q)
+		if len(xs) == 0 {
+			return nil
+		}
+
+		if missing == nil || len(xs) < len(missing) {
+			missing = xs
+		}
+	}
+
+	return missing
+}
+
+func randomTransaction(ts []*MockTransaction, fn func(*MockTransaction) bool) *MockTransaction {
+	xs := []*MockTransaction{}
+
+	for _, t := range ts {
+		if fn(t) {
+			xs = append(xs, t)
+		}
+	}
+
+	if len(xs) == 0 {
+		return nil
+	}
+
+	return xs[randomInt(len(xs))]
+}
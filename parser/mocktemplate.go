@@ -0,0 +1,229 @@
+package parser
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Mock bodies containing these delimiters are executed as templates. They
+// differ from "{{" and "}}" which are consumed by the blueprint loader.
+const (
+	mockLeftDelim  = "<%"
+	mockRightDelim = "%>"
+)
+
+// mockRequestLimit is the largest request body read for mock templates
+const mockRequestLimit = 1 << 20
+
+// errMockRequestTooLarge is returned when request body exceeds mockRequestLimit
+var errMockRequestTooLarge = errors.New("request body too large")
+
+// MockRequest is the data available to mock body templates
+type MockRequest struct {
+	Method  string
+	Path    string
+	Params  map[string]string
+	Query   url.Values
+	Headers http.Header
+	Body    interface{}
+}
+
+// Param returns value of path parameter
+func (r *MockRequest) Param(key string) string {
+	return r.Params[key]
+}
+
+// QueryParam returns the first value of query parameter
+func (r *MockRequest) QueryParam(key string) string {
+	return r.Query.Get(key)
+}
+
+// Header returns the first value of request header
+func (r *MockRequest) Header(key string) string {
+	return r.Headers.Get(key)
+}
+
+// Field returns field of request body by dotted path, e.g. "user.name" or
+// "items.0.id". It returns nil when the field is missing.
+func (r *MockRequest) Field(path string) interface{} {
+	v := r.Body
+
+	for _, k := range strings.Split(path, ".") {
+		switch x := v.(type) {
+		case map[string]interface{}:
+			v = x[k]
+		case []interface{}:
+			i, err := strconv.Atoi(k)
+			if err != nil || i < 0 || i >= len(x) {
+				return nil
+			}
+
+			v = x[i]
+		default:
+			return nil
+		}
+	}
+
+	return v
+}
+
+// newMockRequest returns template data of r, reading no more than
+// mockRequestLimit bytes of its body
+func newMockRequest(r *http.Request, params map[string]string) (*MockRequest, error) {
+	m := &MockRequest{
+		Method:  r.Method,
+		Path:    r.URL.Path,
+		Params:  params,
+		Query:   r.URL.Query(),
+		Headers: r.Header,
+	}
+
+	if r.Body == nil {
+		return m, nil
+	}
+
+	b, err := ioutil.ReadAll(io.LimitReader(r.Body, mockRequestLimit+1))
+	if len(b) > mockRequestLimit {
+		return nil, errMockRequestTooLarge
+	}
+
+	if err != nil || len(b) == 0 {
+		return m, nil
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	if err := json.Unmarshal(b, &m.Body); err == nil {
+		return m, nil
+	}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		if vs, err := url.ParseQuery(string(b)); err == nil {
+			form := map[string]interface{}{}
+			for k := range vs {
+				form[k] = vs.Get(k)
+			}
+
+			m.Body = form
+			return m, nil
+		}
+	}
+
+	m.Body = string(b)
+	return m, nil
+}
+
+func isMockTemplate(s string) bool {
+	return strings.Contains(s, mockLeftDelim)
+}
+
+func parseMockTemplate(name, s string) (*template.Template, error) {
+	return template.New(name).Delims(mockLeftDelim, mockRightDelim).Funcs(mockFuncs).Parse(s)
+}
+
+var mockFuncs = template.FuncMap{
+	"uuid":      fakeUUID,
+	"name":      fakeName,
+	"email":     fakeEmail,
+	"timestamp": fakeTimestamp,
+	"int":       fakeInt,
+	"seq":       seq,
+	"json":      jsonValue,
+}
+
+var (
+	fakeFirstNames = []string{"Alice", "Bob", "Carol", "Dave", "Eve", "Frank", "Grace", "Heidi", "Ivan", "Judy", "Mallory", "Oscar", "Peggy", "Trent", "Victor", "Walter"}
+	fakeLastNames  = []string{"Anderson", "Brown", "Clark", "Davis", "Evans", "Garcia", "Harris", "Johnson", "Lee", "Miller", "Moore", "Smith", "Taylor", "Walker", "White", "Wilson"}
+	fakeDomains    = []string{"example.com", "example.net", "example.org"}
+)
+
+// randomInt returns random integer in [0, n)
+func randomInt(n int) int {
+	if n <= 0 {
+		return 0
+	}
+
+	x, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0
+	}
+
+	return int(x.Int64())
+}
+
+func pick(xs []string) string {
+	return xs[randomInt(len(xs))]
+}
+
+// fakeUUID returns random version 4 UUID
+func fakeUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// fakeName returns random full name
+func fakeName() string {
+	return pick(fakeFirstNames) + " " + pick(fakeLastNames)
+}
+
+// fakeEmail returns random email address
+func fakeEmail() string {
+	return strings.ToLower(pick(fakeFirstNames)+"."+pick(fakeLastNames)) + "@" + pick(fakeDomains)
+}
+
+// fakeTimestamp returns current time formatted in RFC 3339, or in the given
+// Go time layout
+func fakeTimestamp(layout ...string) string {
+	if len(layout) > 0 {
+		return time.Now().Format(layout[0])
+	}
+
+	return time.Now().Format(time.RFC3339)
+}
+
+// fakeInt returns random integer in [min, max]
+func fakeInt(min, max int) int {
+	if max < min {
+		min, max = max, min
+	}
+
+	return min + randomInt(max-min+1)
+}
+
+// seq returns [0, 1, ..., n-1] for ranging in templates
+func seq(n int) []int {
+	xs := []int{}
+
+	for i := 0; i < n; i++ {
+		xs = append(xs, i)
+	}
+
+	return xs
+}
+
+// jsonValue encodes v as JSON, useful for echoing strings from request
+func jsonValue(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
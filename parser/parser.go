@@ -40,7 +40,13 @@ func Validate(r io.Reader, engine Parser) (*api.API, error) {
 
 // Load reads API blueprint from file as blueprint.API struct using selected Parser
 func Load(name string, engine Parser) (*api.API, error) {
-	b, err := Read(name)
+	return LoadSource(Source{Name: name}, engine)
+}
+
+// LoadSource reads API blueprint of source as blueprint.API struct using
+// selected Parser
+func LoadSource(src Source, engine Parser) (*api.API, error) {
+	b, err := ReadSource(src)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,8 @@
 package parser_test
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -59,3 +61,73 @@ func TestRead_seed(t *testing.T) {
 	assert.Contains(t, string(b), `seeds usage`)
 	assert.Contains(t, string(b), `user-related`)
 }
+
+func TestReadSource_seed(t *testing.T) {
+	b, err := snowboard.ReadSource(snowboard.Source{
+		Name: "../fixtures/seeds/API.apib",
+		Seed: "staging.json",
+	})
+	assert.Nil(t, err)
+	assert.Contains(t, string(b), `201`)
+	assert.Contains(t, string(b), `seed overrides`)
+
+	_, err = snowboard.ReadSource(snowboard.Source{
+		Name: "../fixtures/seeds/API.apib",
+		Seed: "missing.json",
+	})
+	assert.NotNil(t, err)
+}
+
+func TestReadSource_reader(t *testing.T) {
+	f, err := os.Open("../fixtures/seeds/API.apib")
+	assert.Nil(t, err)
+	defer f.Close()
+
+	b, err := snowboard.ReadSource(snowboard.Source{
+		Name:    "-",
+		Reader:  f,
+		BaseDir: "../fixtures/seeds",
+	})
+	assert.Nil(t, err)
+	assert.Contains(t, string(b), `seeds usage`)
+	assert.Contains(t, string(b), `user-related`)
+}
+
+func TestExpand(t *testing.T) {
+	x, err := snowboard.Expand(snowboard.Source{Name: "../fixtures/partials/API.apib"})
+	assert.Nil(t, err)
+
+	b, err := snowboard.Read("../fixtures/partials/API.apib")
+	assert.Nil(t, err)
+	assert.Equal(t, string(b), string(x.Body))
+
+	lines := strings.Split(strings.TrimSuffix(string(x.Body), "\n"), "\n")
+	assert.Equal(t, len(lines), len(x.Origins))
+
+	i := strings.Index(string(x.Body), "# Group Messages")
+	o, col := x.Locate(i)
+	assert.Equal(t, "messages.apib", filepath.Base(o.File))
+	assert.Equal(t, 0, o.Line)
+	assert.Equal(t, 0, col)
+
+	i = strings.Index(string(x.Body), "Group of all user-related")
+	o, _ = x.Locate(i)
+	assert.Equal(t, "users.apib", filepath.Base(o.File))
+	assert.Equal(t, 2, o.Line)
+
+	o, _ = x.Locate(strings.Index(string(x.Body), "This API example"))
+	assert.Equal(t, "API.apib", filepath.Base(o.File))
+	assert.Equal(t, 4, o.Line)
+}
+
+func TestFiles(t *testing.T) {
+	fs, err := snowboard.Files("../fixtures/seeds/API.apib")
+	assert.Nil(t, err)
+
+	names := []string{}
+	for _, f := range fs {
+		names = append(names, filepath.Base(f))
+	}
+
+	assert.Equal(t, []string{"API.apib", "seed.json", "messages.apib", "users.apib"}, names)
+}
@@ -56,9 +56,21 @@ func colorize(v interface{}) string {
 }
 
 func alias(s string) string {
-	switch s {
-	case "application/json":
+	t := strings.TrimSpace(strings.SplitN(s, ";", 2)[0])
+
+	switch {
+	case t == "application/json", strings.HasSuffix(t, "+json"):
 		return "json"
+	case t == "application/xml", t == "text/xml", strings.HasSuffix(t, "+xml"):
+		return "xml"
+	case t == "text/html":
+		return "html"
+	case t == "application/x-yaml", t == "application/yaml", t == "text/yaml":
+		return "yaml"
+	case t == "application/javascript", t == "text/javascript":
+		return "javascript"
+	case t == "text/css":
+		return "css"
 	}
 
 	return ""
@@ -94,13 +106,37 @@ func markdown(input []byte) string {
 	return bf.String()
 }
 
+// HTMLOptions customizes HTML rendering
+type HTMLOptions struct {
+	// Console enables interactive console for sending requests from browser
+	Console bool
+	// BaseURL is where console sends requests to, instead of documented HOST
+	BaseURL string
+}
+
 // HTML renders blueprint.API struct as HTML document
 func HTML(tpl string, w io.Writer, b *api.API) error {
+	return HTMLWithOptions(tpl, w, b, HTMLOptions{})
+}
+
+// HTMLWithOptions renders blueprint.API struct as HTML document using options
+func HTMLWithOptions(tpl string, w io.Writer, b *api.API, o HTMLOptions) error {
+	if o.BaseURL == "" {
+		o.BaseURL = b.Host()
+	}
+
 	funcMap := template.FuncMap{
 		"markdownize":  markdownize,
 		"parameterize": parameterize,
 		"colorize":     colorize,
 		"alias":        alias,
+		"snippets":     Snippets,
+		"options": func() HTMLOptions {
+			return o
+		},
+		"console": func(t *api.Transition) string {
+			return consoleData(b.Host(), t)
+		},
 	}
 
 	tmpl, err := template.New("html").Funcs(funcMap).Parse(tpl)
@@ -0,0 +1,81 @@
+package parser_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/subosito/snowboard/api"
+	snowboard "github.com/subosito/snowboard/parser"
+)
+
+func renderFixture() *api.API {
+	return &api.API{
+		Title:    "Notes API",
+		Metadata: []api.Metadata{{Key: "HOST", Value: "https://api.example.com"}},
+		ResourceGroups: []api.ResourceGroup{
+			{
+				Title: "Notes",
+				Resources: []*api.Resource{
+					{
+						Title: "Note",
+						Href:  api.Href{Path: "/notes/{id}"},
+						Transitions: []*api.Transition{
+							{
+								Title:     "Retrieve a Note",
+								Method:    "GET",
+								URL:       "https://api.example.com/notes/{id}",
+								Permalink: "notes-note-retrieve-a-note",
+								Href: api.Href{
+									Parameters: []api.Parameter{{Key: "id", Value: "1", Kind: "number", Required: true}},
+								},
+								Transactions: []api.Transaction{
+									{
+										Request: api.Request{Method: "GET"},
+										Response: api.Response{
+											StatusCode: 200,
+											Headers:    []api.Header{{Key: "Content-Type", Value: "application/json"}},
+											Body:       api.Asset{ContentType: "application/json", Body: `{"id": 1}`},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestHTML(t *testing.T) {
+	tpl, err := ioutil.ReadFile("../templates/alpha.html")
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+
+	err = snowboard.HTML(string(tpl), &buf, renderFixture())
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), "Retrieve a Note")
+	assert.Contains(t, buf.String(), "curl &#39;https://api.example.com/notes/1&#39;")
+}
+
+func TestHTMLWithOptions_console(t *testing.T) {
+	tpl, err := ioutil.ReadFile("../templates/alpha.html")
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+
+	err = snowboard.HTMLWithOptions(string(tpl), &buf, renderFixture(), snowboard.HTMLOptions{Console: true})
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), "snowboardConsole")
+	assert.Contains(t, buf.String(), `name="baseURL" value="https://api.example.com"`)
+	assert.Contains(t, buf.String(), `data-param="id" value="1"`)
+
+	buf.Reset()
+
+	err = snowboard.HTML(string(tpl), &buf, renderFixture())
+	assert.Nil(t, err)
+	assert.NotContains(t, buf.String(), "snowboardConsole")
+}
@@ -0,0 +1,290 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// jsonSchema is the subset of JSON Schema draft 4 produced by drafter from
+// MSON attributes, plus common validation keywords of hand written schemas
+type jsonSchema struct {
+	Type                 interface{}            `json:"type"`
+	Ref                  string                 `json:"$ref"`
+	Enum                 []interface{}          `json:"enum"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+	Items                json.RawMessage        `json:"items"`
+	AllOf                []*jsonSchema          `json:"allOf"`
+	AnyOf                []*jsonSchema          `json:"anyOf"`
+	OneOf                []*jsonSchema          `json:"oneOf"`
+	Definitions          map[string]*jsonSchema `json:"definitions"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	MinItems             *int                   `json:"minItems"`
+	MaxItems             *int                   `json:"maxItems"`
+	Pattern              string                 `json:"pattern"`
+}
+
+// schemaValidator validates decoded JSON values, resolving references
+// against definitions of the root schema
+type schemaValidator struct {
+	definitions map[string]*jsonSchema
+	depth       int
+}
+
+// validateJSON returns violations of JSON body against schema. Error is
+// returned when either of them is not valid JSON.
+func validateJSON(schema, body string) ([]string, error) {
+	var s jsonSchema
+
+	if err := json.Unmarshal([]byte(schema), &s); err != nil {
+		return nil, fmt.Errorf("Unable to read schema: %s", err)
+	}
+
+	var v interface{}
+
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		return nil, fmt.Errorf("Unable to read body: %s", err)
+	}
+
+	sv := &schemaValidator{definitions: s.Definitions}
+	return sv.validate(&s, v, "$"), nil
+}
+
+func (sv *schemaValidator) validate(s *jsonSchema, v interface{}, at string) []string {
+	if s == nil || sv.depth > 32 {
+		return nil
+	}
+
+	sv.depth++
+	defer func() { sv.depth-- }()
+
+	if s.Ref != "" {
+		d, ok := sv.definitions[strings.TrimPrefix(s.Ref, "#/definitions/")]
+		if !ok {
+			return nil
+		}
+
+		return sv.validate(d, v, at)
+	}
+
+	if types := schemaTypes(s.Type); len(types) > 0 && !containsType(types, v) {
+		return []string{fmt.Sprintf("%s: expected %s, got %s", at, strings.Join(types, " or "), jsonKind(v))}
+	}
+
+	errs := []string{}
+
+	if len(s.Enum) > 0 && !containsValue(s.Enum, v) {
+		errs = append(errs, fmt.Sprintf("%s: %s is not one of allowed values", at, compactJSON(v)))
+	}
+
+	switch x := v.(type) {
+	case map[string]interface{}:
+		errs = append(errs, sv.validateObject(s, x, at)...)
+	case []interface{}:
+		errs = append(errs, sv.validateArray(s, x, at)...)
+	case string:
+		n := utf8.RuneCountInString(x)
+
+		if s.MinLength != nil && n < *s.MinLength {
+			errs = append(errs, fmt.Sprintf("%s: shorter than %d characters", at, *s.MinLength))
+		}
+
+		if s.MaxLength != nil && n > *s.MaxLength {
+			errs = append(errs, fmt.Sprintf("%s: longer than %d characters", at, *s.MaxLength))
+		}
+
+		if s.Pattern != "" {
+			if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(x) {
+				errs = append(errs, fmt.Sprintf("%s: does not match pattern %s", at, s.Pattern))
+			}
+		}
+	case float64:
+		if s.Minimum != nil && x < *s.Minimum {
+			errs = append(errs, fmt.Sprintf("%s: less than %v", at, *s.Minimum))
+		}
+
+		if s.Maximum != nil && x > *s.Maximum {
+			errs = append(errs, fmt.Sprintf("%s: greater than %v", at, *s.Maximum))
+		}
+	}
+
+	for _, x := range s.AllOf {
+		errs = append(errs, sv.validate(x, v, at)...)
+	}
+
+	if len(s.AnyOf) > 0 && sv.matches(s.AnyOf, v, at) == 0 {
+		errs = append(errs, fmt.Sprintf("%s: matches none of anyOf schemas", at))
+	}
+
+	if len(s.OneOf) > 0 {
+		if n := sv.matches(s.OneOf, v, at); n != 1 {
+			errs = append(errs, fmt.Sprintf("%s: matches %d of oneOf schemas, expected exactly one", at, n))
+		}
+	}
+
+	return errs
+}
+
+func (sv *schemaValidator) validateObject(s *jsonSchema, x map[string]interface{}, at string) []string {
+	errs := []string{}
+
+	for _, k := range s.Required {
+		if _, ok := x[k]; !ok {
+			errs = append(errs, fmt.Sprintf("%s: missing required property %q", at, k))
+		}
+	}
+
+	keys := []string{}
+	for k := range x {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if p, ok := s.Properties[k]; ok {
+			errs = append(errs, sv.validate(p, x[k], at+"."+k)...)
+			continue
+		}
+
+		switch ap := strings.TrimSpace(string(s.AdditionalProperties)); {
+		case ap == "false":
+			errs = append(errs, fmt.Sprintf("%s: undocumented property %q", at, k))
+		case strings.HasPrefix(ap, "{"):
+			var p jsonSchema
+			if json.Unmarshal(s.AdditionalProperties, &p) == nil {
+				errs = append(errs, sv.validate(&p, x[k], at+"."+k)...)
+			}
+		}
+	}
+
+	return errs
+}
+
+func (sv *schemaValidator) validateArray(s *jsonSchema, x []interface{}, at string) []string {
+	errs := []string{}
+
+	if s.MinItems != nil && len(x) < *s.MinItems {
+		errs = append(errs, fmt.Sprintf("%s: fewer than %d items", at, *s.MinItems))
+	}
+
+	if s.MaxItems != nil && len(x) > *s.MaxItems {
+		errs = append(errs, fmt.Sprintf("%s: more than %d items", at, *s.MaxItems))
+	}
+
+	items := strings.TrimSpace(string(s.Items))
+
+	switch {
+	case strings.HasPrefix(items, "{"):
+		var p jsonSchema
+		if json.Unmarshal(s.Items, &p) == nil {
+			for i, y := range x {
+				errs = append(errs, sv.validate(&p, y, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+	case strings.HasPrefix(items, "["):
+		// tuple items as produced by drafter for fixed arrays
+		ps := []*jsonSchema{}
+		if json.Unmarshal(s.Items, &ps) == nil {
+			for i, y := range x {
+				if i < len(ps) {
+					errs = append(errs, sv.validate(ps[i], y, fmt.Sprintf("%s[%d]", at, i))...)
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// matches returns number of schemas v is valid against
+func (sv *schemaValidator) matches(ss []*jsonSchema, v interface{}, at string) int {
+	n := 0
+
+	for _, s := range ss {
+		if len(sv.validate(s, v, at)) == 0 {
+			n++
+		}
+	}
+
+	return n
+}
+
+func schemaTypes(t interface{}) []string {
+	switch v := t.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		xs := []string{}
+		for _, x := range v {
+			if s, ok := x.(string); ok {
+				xs = append(xs, s)
+			}
+		}
+
+		return xs
+	}
+
+	return nil
+}
+
+func containsType(types []string, v interface{}) bool {
+	k := jsonKind(v)
+
+	for _, t := range types {
+		if t == k || t == "number" && k == "integer" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// jsonKind returns JSON Schema type of decoded value
+func jsonKind(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if x == math.Trunc(x) && !math.IsInf(x, 0) {
+			return "integer"
+		}
+
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+
+	return "unknown"
+}
+
+func containsValue(xs []interface{}, v interface{}) bool {
+	for _, x := range xs {
+		if reflect.DeepEqual(x, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func compactJSON(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,292 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/subosito/snowboard/api"
+)
+
+// Snippet is a ready to use code sample which performs a request
+type Snippet struct {
+	Name     string
+	Language string
+	Code     string
+}
+
+type snippetRequest struct {
+	Method  string
+	URL     string
+	Headers []api.Header
+	Body    string
+}
+
+// Snippets generates code samples for transaction request using curl, HTTPie,
+// Go, Python, JavaScript and HAR
+func Snippets(t *api.Transition, x api.Transaction) []Snippet {
+	r := newSnippetRequest(t, x)
+
+	return []Snippet{
+		{Name: "curl", Language: "bash", Code: r.curl()},
+		{Name: "HTTPie", Language: "bash", Code: r.httpie()},
+		{Name: "Go", Language: "go", Code: r.golang()},
+		{Name: "Python", Language: "python", Code: r.python()},
+		{Name: "JavaScript", Language: "javascript", Code: r.javascript()},
+		{Name: "HAR", Language: "json", Code: r.har()},
+	}
+}
+
+func newSnippetRequest(t *api.Transition, x api.Transaction) *snippetRequest {
+	r := &snippetRequest{
+		Method:  x.Request.Method,
+		URL:     exampleURL(t),
+		Headers: x.Request.Headers,
+		Body:    x.Request.Body.Body,
+	}
+
+	if r.Method == "" {
+		r.Method = t.Method
+	}
+
+	if r.Method == "" {
+		r.Method = "GET"
+	}
+
+	if ct := x.Request.Body.ContentType; ct != "" && !r.hasHeader("Content-Type") {
+		r.Headers = append(r.Headers, api.Header{Key: "Content-Type", Value: ct})
+	}
+
+	return r
+}
+
+// exampleURL expands transition URL using example values of its parameters.
+// Required parameters without example are substituted by their names.
+func exampleURL(t *api.Transition) string {
+	vars := map[string]string{}
+
+	for _, p := range t.Href.Parameters {
+		switch {
+		case p.Value != "":
+			vars[p.Key] = p.Value
+		case p.Required:
+			vars[p.Key] = p.Key
+		}
+	}
+
+	return expandURI(t.URL, vars)
+}
+
+func (r *snippetRequest) hasHeader(key string) bool {
+	for _, h := range r.Headers {
+		if strings.EqualFold(h.Key, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *snippetRequest) curl() string {
+	xs := []string{}
+
+	if r.Method == "GET" {
+		xs = append(xs, "curl "+shellQuote(r.URL))
+	} else {
+		xs = append(xs, fmt.Sprintf("curl -X %s %s", r.Method, shellQuote(r.URL)))
+	}
+
+	for _, h := range r.Headers {
+		xs = append(xs, "  -H "+shellQuote(h.Key+": "+h.Value))
+	}
+
+	if r.Body != "" {
+		xs = append(xs, "  --data "+shellQuote(r.Body))
+	}
+
+	return strings.Join(xs, " \\\n")
+}
+
+func (r *snippetRequest) httpie() string {
+	xs := []string{fmt.Sprintf("http %s %s", r.Method, shellQuote(r.URL))}
+
+	for _, h := range r.Headers {
+		xs = append(xs, "  "+shellQuote(h.Key+":"+h.Value))
+	}
+
+	s := strings.Join(xs, " \\\n")
+
+	if r.Body != "" {
+		s = fmt.Sprintf("echo %s | %s", shellQuote(r.Body), s)
+	}
+
+	return s
+}
+
+func (r *snippetRequest) golang() string {
+	var buf bytes.Buffer
+
+	body := "nil"
+	if r.Body != "" {
+		body = fmt.Sprintf("strings.NewReader(%s)", goQuote(r.Body))
+	}
+
+	fmt.Fprintln(&buf, "package main")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "import (")
+	fmt.Fprintln(&buf, "\t\"fmt\"")
+	fmt.Fprintln(&buf, "\t\"io/ioutil\"")
+	fmt.Fprintln(&buf, "\t\"net/http\"")
+	if r.Body != "" {
+		fmt.Fprintln(&buf, "\t\"strings\"")
+	}
+	fmt.Fprintln(&buf, ")")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "func main() {")
+	fmt.Fprintf(&buf, "\treq, err := http.NewRequest(%q, %q, %s)\n", r.Method, r.URL, body)
+	fmt.Fprintln(&buf, "\tif err != nil {")
+	fmt.Fprintln(&buf, "\t\tpanic(err)")
+	fmt.Fprintln(&buf, "\t}")
+	fmt.Fprintln(&buf)
+
+	for _, h := range r.Headers {
+		fmt.Fprintf(&buf, "\treq.Header.Set(%q, %q)\n", h.Key, h.Value)
+	}
+
+	if len(r.Headers) > 0 {
+		fmt.Fprintln(&buf)
+	}
+
+	fmt.Fprintln(&buf, "\tresp, err := http.DefaultClient.Do(req)")
+	fmt.Fprintln(&buf, "\tif err != nil {")
+	fmt.Fprintln(&buf, "\t\tpanic(err)")
+	fmt.Fprintln(&buf, "\t}")
+	fmt.Fprintln(&buf, "\tdefer resp.Body.Close()")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "\tb, _ := ioutil.ReadAll(resp.Body)")
+	fmt.Fprintln(&buf, "\tfmt.Println(resp.Status)")
+	fmt.Fprintln(&buf, "\tfmt.Println(string(b))")
+	fmt.Fprint(&buf, "}")
+
+	return buf.String()
+}
+
+func (r *snippetRequest) python() string {
+	var buf bytes.Buffer
+
+	fmt.Fprintln(&buf, "import requests")
+	fmt.Fprintln(&buf)
+
+	args := []string{jsQuote(r.Method), jsQuote(r.URL)}
+
+	if len(r.Headers) > 0 {
+		xs := []string{}
+		for _, h := range r.Headers {
+			xs = append(xs, fmt.Sprintf("    %s: %s,", jsQuote(h.Key), jsQuote(h.Value)))
+		}
+
+		fmt.Fprintf(&buf, "headers = {\n%s\n}\n", strings.Join(xs, "\n"))
+		args = append(args, "headers=headers")
+	}
+
+	if r.Body != "" {
+		fmt.Fprintf(&buf, "data = %s\n", jsQuote(r.Body))
+		args = append(args, "data=data")
+	}
+
+	if len(r.Headers) > 0 || r.Body != "" {
+		fmt.Fprintln(&buf)
+	}
+
+	fmt.Fprintf(&buf, "response = requests.request(%s)\n", strings.Join(args, ", "))
+	fmt.Fprintln(&buf, "print(response.status_code)")
+	fmt.Fprint(&buf, "print(response.text)")
+
+	return buf.String()
+}
+
+func (r *snippetRequest) javascript() string {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "fetch(%s, {\n", jsQuote(r.URL))
+	fmt.Fprintf(&buf, "  method: %s,\n", jsQuote(r.Method))
+
+	if len(r.Headers) > 0 {
+		fmt.Fprintln(&buf, "  headers: {")
+		for _, h := range r.Headers {
+			fmt.Fprintf(&buf, "    %s: %s,\n", jsQuote(h.Key), jsQuote(h.Value))
+		}
+		fmt.Fprintln(&buf, "  },")
+	}
+
+	if r.Body != "" {
+		fmt.Fprintf(&buf, "  body: %s,\n", jsQuote(r.Body))
+	}
+
+	fmt.Fprintln(&buf, "})")
+	fmt.Fprintln(&buf, "  .then(response => response.text())")
+	fmt.Fprint(&buf, "  .then(text => console.log(text));")
+
+	return buf.String()
+}
+
+func (r *snippetRequest) har() string {
+	h := harRequest{
+		Method:      r.Method,
+		URL:         r.URL,
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []harHeader{},
+		Headers:     []harHeader{},
+		QueryString: harQueryString(r.URL),
+		HeadersSize: -1,
+		BodySize:    len(r.Body),
+	}
+
+	for _, x := range r.Headers {
+		h.Headers = append(h.Headers, harHeader{Name: x.Key, Value: x.Value})
+	}
+
+	if r.Body != "" {
+		mt := ""
+		for _, x := range r.Headers {
+			if strings.EqualFold(x.Key, "Content-Type") {
+				mt = x.Value
+			}
+		}
+
+		h.PostData = &harPostData{MimeType: mt, Text: r.Body}
+	}
+
+	b, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return ""
+	}
+
+	return string(b)
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+func goQuote(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+
+	return "`" + s + "`"
+}
+
+// jsQuote quotes string as JSON string literal, which is valid for both
+// JavaScript and Python
+func jsQuote(s string) string {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+
+	return strings.TrimSpace(buf.String())
+}
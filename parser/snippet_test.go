@@ -0,0 +1,46 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/subosito/snowboard/api"
+	snowboard "github.com/subosito/snowboard/parser"
+)
+
+func TestSnippets(t *testing.T) {
+	tr := &api.Transition{
+		Method: "POST",
+		URL:    "https://api.example.com/notes/{id}{?sort,limit}",
+		Href: api.Href{
+			Parameters: []api.Parameter{
+				{Key: "id", Value: "42", Required: true},
+				{Key: "sort", Value: "created at"},
+				{Key: "limit"},
+			},
+		},
+	}
+
+	x := api.Transaction{
+		Request: api.Request{
+			Method:  "POST",
+			Headers: []api.Header{{Key: "Authorization", Value: "Bearer it's"}},
+			Body:    api.Asset{ContentType: "application/json", Body: `{"title": "Hello"}`},
+		},
+	}
+
+	ss := snowboard.Snippets(tr, x)
+	assert.Len(t, ss, 6)
+
+	assert.Equal(t, "curl", ss[0].Name)
+	assert.Contains(t, ss[0].Code, `curl -X POST 'https://api.example.com/notes/42?sort=created%20at'`)
+	assert.Contains(t, ss[0].Code, `-H 'Authorization: Bearer it'\''s'`)
+	assert.Contains(t, ss[0].Code, `-H 'Content-Type: application/json'`)
+	assert.Contains(t, ss[0].Code, `--data '{"title": "Hello"}'`)
+
+	assert.Contains(t, ss[2].Code, "strings.NewReader(`{\"title\": \"Hello\"}`)")
+	assert.Contains(t, ss[3].Code, `data = "{\"title\": \"Hello\"}"`)
+	assert.Contains(t, ss[4].Code, `method: "POST",`)
+	assert.Contains(t, ss[5].Code, `"name": "sort",`)
+	assert.Contains(t, ss[5].Code, `"value": "created at"`)
+}
@@ -0,0 +1,239 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/subosito/snowboard/api"
+)
+
+// Endpoint is a transition along with resource and group it belongs to
+type Endpoint struct {
+	Group      string
+	Resource   *api.Resource
+	Transition *api.Transition
+}
+
+// EndpointFilter selects endpoints by path, method or group. Empty fields
+// match anything.
+type EndpointFilter struct {
+	Pattern string
+	Method  string
+	Group   string
+}
+
+// Endpoints flattens all transitions of blueprint.API struct
+func Endpoints(b *api.API) []Endpoint {
+	es := []Endpoint{}
+
+	for _, g := range b.ResourceGroups {
+		for _, x := range g.Resources {
+			for _, t := range x.Transitions {
+				es = append(es, Endpoint{Group: g.Title, Resource: x, Transition: t})
+			}
+		}
+	}
+
+	return es
+}
+
+// Filter returns endpoints which match the filter
+func (f EndpointFilter) Filter(es []Endpoint) []Endpoint {
+	xs := []Endpoint{}
+
+	for _, e := range es {
+		if f.Match(e) {
+			xs = append(xs, e)
+		}
+	}
+
+	return xs
+}
+
+// Match reports whether endpoint matches the filter. Pattern is matched
+// case-insensitively against path, title and permalink.
+func (f EndpointFilter) Match(e Endpoint) bool {
+	if f.Method != "" && !strings.EqualFold(f.Method, e.Transition.Method) {
+		return false
+	}
+
+	if f.Group != "" && !containsFold(e.Group, f.Group) {
+		return false
+	}
+
+	if f.Pattern == "" {
+		return true
+	}
+
+	return containsFold(e.Transition.URL, f.Pattern) ||
+		containsFold(e.Transition.Title, f.Pattern) ||
+		containsFold(e.Transition.Permalink, f.Pattern)
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+var ansiColors = map[string]string{
+	"green":  "32",
+	"blue":   "34",
+	"teal":   "36",
+	"violet": "35",
+	"red":    "31",
+	"orange": "33",
+}
+
+// ansi paints s using terminal color of colorize semantic
+func ansi(s string, color bool, v interface{}) string {
+	if !color {
+		return s
+	}
+
+	if c, ok := ansiColors[colorize(v)]; ok {
+		return "\x1b[" + c + "m" + s + "\x1b[0m"
+	}
+
+	return s
+}
+
+func bold(s string, color bool) string {
+	if !color {
+		return s
+	}
+
+	return "\x1b[1m" + s + "\x1b[0m"
+}
+
+// TerminalList prints endpoints as table, one endpoint per line
+func TerminalList(w io.Writer, es []Endpoint, color bool) error {
+	var buf bytes.Buffer
+
+	mw, uw, tw := 0, 0, 0
+
+	for _, e := range es {
+		mw = maxInt(mw, len(e.Transition.Method))
+		uw = maxInt(uw, utf8.RuneCountInString(e.Transition.URL))
+		tw = maxInt(tw, utf8.RuneCountInString(transitionTitle(e.Transition)))
+	}
+
+	for _, e := range es {
+		m := e.Transition.Method
+
+		fmt.Fprintf(&buf, "%s  %s  %s  %s\n", ansi(pad(m, mw), color, m), pad(e.Transition.URL, uw), pad(transitionTitle(e.Transition), tw), e.Group)
+	}
+
+	_, err := io.Copy(w, &buf)
+	return err
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+func pad(s string, n int) string {
+	return s + strings.Repeat(" ", n-utf8.RuneCountInString(s))
+}
+
+// TerminalDetail prints full details of endpoint, including parameters,
+// requests and responses
+func TerminalDetail(w io.Writer, e Endpoint, color bool) error {
+	t := e.Transition
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "%s %s\n", ansi(t.Method, color, t.Method), bold(t.URL, color))
+	fmt.Fprintf(&buf, "%s\n", bold(transitionTitle(t), color))
+
+	if e.Group != "" {
+		fmt.Fprintf(&buf, "%s / %s\n", e.Group, resourceTitle(e.Resource))
+	} else {
+		fmt.Fprintf(&buf, "%s\n", resourceTitle(e.Resource))
+	}
+
+	if s := strings.TrimSpace(t.Description); s != "" {
+		fmt.Fprintf(&buf, "\n%s\n", indent(s, "  "))
+	}
+
+	if len(t.Href.Parameters) > 0 {
+		fmt.Fprintf(&buf, "\n%s\n", bold("PARAMETERS", color))
+
+		tw := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+		for _, p := range t.Href.Parameters {
+			required := "optional"
+			if p.Required {
+				required = "required"
+			}
+
+			example := ""
+			if p.Value != "" {
+				example = "e.g. " + p.Value
+			}
+
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n", p.Key, p.Kind, required, example, strings.Join(strings.Fields(p.Description), " "))
+		}
+		tw.Flush()
+	}
+
+	for _, x := range t.Transactions {
+		fmt.Fprintf(&buf, "\n%s\n", bold(strings.TrimSpace("REQUEST "+x.Request.Title), color))
+		writeTerminalAssets(&buf, x.Request.Description, x.Request.Headers, x.Request.Body)
+
+		code := strconv.Itoa(x.Response.StatusCode)
+		fmt.Fprintf(&buf, "\n%s %s\n", bold("RESPONSE", color), ansi(code, color, code))
+		writeTerminalAssets(&buf, x.Response.Description, x.Response.Headers, x.Response.Body)
+	}
+
+	_, err := io.Copy(w, &buf)
+	return err
+}
+
+func writeTerminalAssets(w io.Writer, description string, hs []api.Header, body api.Asset) {
+	if s := strings.TrimSpace(description); s != "" {
+		fmt.Fprintf(w, "%s\n", indent(s, "  "))
+	}
+
+	for _, h := range hs {
+		fmt.Fprintf(w, "  %s: %s\n", h.Key, h.Value)
+	}
+
+	if body.Body != "" {
+		fmt.Fprintf(w, "\n%s\n", indent(prettyBody(body), "    "))
+	}
+}
+
+// prettyBody indents JSON body, other bodies are returned as is
+func prettyBody(a api.Asset) string {
+	s := strings.TrimRight(a.Body, "\n")
+
+	if alias(a.ContentType) != "json" {
+		return s
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(s), "", "  "); err != nil {
+		return s
+	}
+
+	return buf.String()
+}
+
+func indent(s, prefix string) string {
+	xs := strings.Split(s, "\n")
+
+	for i, x := range xs {
+		if x != "" {
+			xs[i] = prefix + x
+		}
+	}
+
+	return strings.Join(xs, "\n")
+}
@@ -0,0 +1,37 @@
+package parser_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	snowboard "github.com/subosito/snowboard/parser"
+)
+
+func TestEndpointFilter(t *testing.T) {
+	es := snowboard.Endpoints(renderFixture())
+	assert.Len(t, es, 1)
+
+	assert.Len(t, snowboard.EndpointFilter{Pattern: "/NOTES"}.Filter(es), 1)
+	assert.Len(t, snowboard.EndpointFilter{Method: "get", Group: "notes"}.Filter(es), 1)
+	assert.Len(t, snowboard.EndpointFilter{Method: "POST"}.Filter(es), 0)
+	assert.Len(t, snowboard.EndpointFilter{Group: "users"}.Filter(es), 0)
+}
+
+func TestTerminal(t *testing.T) {
+	es := snowboard.Endpoints(renderFixture())
+
+	var buf bytes.Buffer
+
+	err := snowboard.TerminalList(&buf, es, true)
+	assert.Nil(t, err)
+	assert.Equal(t, "\x1b[32mGET\x1b[0m  https://api.example.com/notes/{id}  Retrieve a Note  Notes\n", buf.String())
+
+	buf.Reset()
+
+	err = snowboard.TerminalDetail(&buf, es[0], false)
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), "GET https://api.example.com/notes/{id}\nRetrieve a Note\nNotes / Note\n")
+	assert.Contains(t, buf.String(), "  id  number  required  e.g. 1")
+	assert.Contains(t, buf.String(), "RESPONSE 200\n  Content-Type: application/json\n\n    {\n      \"id\": 1\n    }\n")
+}
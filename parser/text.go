@@ -0,0 +1,309 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/subosito/snowboard/api"
+)
+
+// Document is a named output of multi-file renderer
+type Document struct {
+	Name string
+	Body []byte
+}
+
+// textDialect formats building blocks of plain text documentation
+type textDialect interface {
+	ext() string
+	title(s string) string
+	heading(level int, s, anchor string) string
+	link(s, file, anchor string) string
+	item(level int, s string) string
+	table(header []string, rows [][]string) string
+	code(lang, body string) string
+	strong(s string) string
+	literal(s string) string
+	copy(s string) string
+}
+
+type textRenderer struct {
+	dialect textDialect
+	api     *api.API
+	files   map[int]string
+}
+
+// Markdown renders blueprint.API struct as Markdown document
+func Markdown(w io.Writer, b *api.API) error {
+	return newTextRenderer(markdownDialect{}, b).render(w)
+}
+
+// MarkdownSplit renders blueprint.API struct as Markdown documents, one for
+// each resource group plus index document
+func MarkdownSplit(b *api.API) []Document {
+	return newTextRenderer(markdownDialect{}, b).split()
+}
+
+// AsciiDoc renders blueprint.API struct as AsciiDoc document
+func AsciiDoc(w io.Writer, b *api.API) error {
+	return newTextRenderer(asciidocDialect{}, b).render(w)
+}
+
+// AsciiDocSplit renders blueprint.API struct as AsciiDoc documents, one for
+// each resource group plus index document
+func AsciiDocSplit(b *api.API) []Document {
+	return newTextRenderer(asciidocDialect{}, b).split()
+}
+
+// MarkdownTransition renders single transition as Markdown
+func MarkdownTransition(w io.Writer, t *api.Transition) error {
+	var buf bytes.Buffer
+
+	newTextRenderer(markdownDialect{}, &api.API{}).writeTransition(&buf, t)
+
+	_, err := io.Copy(w, &buf)
+	return err
+}
+
+func newTextRenderer(d textDialect, b *api.API) *textRenderer {
+	return &textRenderer{dialect: d, api: b, files: map[int]string{}}
+}
+
+func (r *textRenderer) render(w io.Writer) error {
+	var buf bytes.Buffer
+
+	r.writeIntroduction(&buf)
+	r.writeTOC(&buf)
+
+	for i := range r.api.ResourceGroups {
+		r.writeGroup(&buf, i)
+	}
+
+	_, err := io.Copy(w, &buf)
+	return err
+}
+
+func (r *textRenderer) split() []Document {
+	seen := map[string]bool{"index": true}
+
+	for i, g := range r.api.ResourceGroups {
+		name := parameterize(g.Title)
+		if name == "" {
+			name = "resources"
+		}
+
+		base := name
+		for n := 2; seen[name]; n++ {
+			name = base + "-" + strconv.Itoa(n)
+		}
+
+		seen[name] = true
+		r.files[i] = name + r.dialect.ext()
+	}
+
+	var buf bytes.Buffer
+
+	r.writeIntroduction(&buf)
+	r.writeTOC(&buf)
+
+	ds := []Document{{Name: "index" + r.dialect.ext(), Body: buf.Bytes()}}
+
+	for i := range r.api.ResourceGroups {
+		var gb bytes.Buffer
+
+		r.writeGroup(&gb, i)
+		ds = append(ds, Document{Name: r.files[i], Body: gb.Bytes()})
+	}
+
+	return ds
+}
+
+func (r *textRenderer) writeIntroduction(w io.Writer) {
+	d := r.dialect
+
+	fmt.Fprintln(w, d.title(r.api.Title))
+
+	if s := strings.TrimSpace(r.api.Description); s != "" {
+		fmt.Fprintln(w, d.copy(s))
+		fmt.Fprintln(w)
+	}
+
+	if h := r.api.Host(); h != "" {
+		fmt.Fprintf(w, "%s %s\n\n", d.strong("Host:"), d.literal(h))
+	}
+}
+
+func (r *textRenderer) writeTOC(w io.Writer) {
+	d := r.dialect
+
+	fmt.Fprintln(w, d.heading(2, "Table of Contents", "table-of-contents"))
+
+	for i, g := range r.api.ResourceGroups {
+		level := 0
+		file := r.files[i]
+
+		if g.Title != "" {
+			fmt.Fprintln(w, d.item(0, d.link(g.Title, file, groupAnchor(g))))
+			level = 1
+		}
+
+		for _, x := range g.Resources {
+			fmt.Fprintln(w, d.item(level, d.link(resourceTitle(x), file, resourceAnchor(g, x))))
+
+			for _, t := range x.Transitions {
+				fmt.Fprintln(w, d.item(level+1, d.link(transitionTitle(t), file, t.Permalink)))
+			}
+		}
+	}
+
+	fmt.Fprintln(w)
+}
+
+func (r *textRenderer) writeGroup(w io.Writer, i int) {
+	d := r.dialect
+	g := r.api.ResourceGroups[i]
+
+	if g.Title != "" {
+		fmt.Fprintln(w, d.heading(2, g.Title, groupAnchor(g)))
+	}
+
+	if s := strings.TrimSpace(g.Description); s != "" {
+		fmt.Fprintln(w, d.copy(s))
+		fmt.Fprintln(w)
+	}
+
+	for _, x := range g.Resources {
+		fmt.Fprintln(w, d.heading(3, resourceTitle(x), resourceAnchor(g, x)))
+
+		if s := strings.TrimSpace(x.Description); s != "" {
+			fmt.Fprintln(w, d.copy(s))
+			fmt.Fprintln(w)
+		}
+
+		for _, t := range x.Transitions {
+			r.writeTransition(w, t)
+		}
+	}
+}
+
+func (r *textRenderer) writeTransition(w io.Writer, t *api.Transition) {
+	d := r.dialect
+
+	fmt.Fprintln(w, d.heading(4, transitionTitle(t), t.Permalink))
+	fmt.Fprintf(w, "%s\n\n", d.literal(strings.TrimSpace(t.Method+" "+t.URL)))
+
+	if s := strings.TrimSpace(t.Description); s != "" {
+		fmt.Fprintln(w, d.copy(s))
+		fmt.Fprintln(w)
+	}
+
+	if len(t.Href.Parameters) > 0 {
+		rows := [][]string{}
+
+		for _, p := range t.Href.Parameters {
+			required := "no"
+			if p.Required {
+				required = "yes"
+			}
+
+			rows = append(rows, []string{d.literal(p.Key), p.Kind, required, p.Value, p.Description})
+		}
+
+		fmt.Fprintln(w, d.strong("Parameters"))
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, d.table([]string{"Name", "Type", "Required", "Example", "Description"}, rows))
+	}
+
+	for _, x := range t.Transactions {
+		r.writeRequest(w, x.Request)
+		r.writeResponse(w, x.Response)
+	}
+}
+
+func (r *textRenderer) writeRequest(w io.Writer, q api.Request) {
+	d := r.dialect
+
+	if q.Title == "" && q.Description == "" && len(q.Headers) == 0 && q.Body.Body == "" {
+		return
+	}
+
+	fmt.Fprintln(w, d.strong(strings.TrimSpace("Request "+q.Title)))
+	fmt.Fprintln(w)
+
+	if s := strings.TrimSpace(q.Description); s != "" {
+		fmt.Fprintln(w, d.copy(s))
+		fmt.Fprintln(w)
+	}
+
+	r.writeAssets(w, q.Headers, q.Body, q.Schema)
+}
+
+func (r *textRenderer) writeResponse(w io.Writer, p api.Response) {
+	d := r.dialect
+
+	fmt.Fprintln(w, d.strong("Response "+strconv.Itoa(p.StatusCode)))
+	fmt.Fprintln(w)
+
+	if s := strings.TrimSpace(p.Description); s != "" {
+		fmt.Fprintln(w, d.copy(s))
+		fmt.Fprintln(w)
+	}
+
+	r.writeAssets(w, p.Headers, p.Body, p.Schema)
+}
+
+func (r *textRenderer) writeAssets(w io.Writer, hs []api.Header, body, schema api.Asset) {
+	d := r.dialect
+
+	if len(hs) > 0 {
+		xs := []string{}
+		for _, h := range hs {
+			xs = append(xs, h.Key+": "+h.Value)
+		}
+
+		fmt.Fprintln(w, d.code("http", strings.Join(xs, "\n")))
+	}
+
+	if body.Body != "" {
+		fmt.Fprintln(w, d.code(alias(body.ContentType), strings.TrimRight(body.Body, "\n")))
+	}
+
+	if schema.Body != "" {
+		fmt.Fprintln(w, d.strong("Schema"))
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, d.code("json", strings.TrimRight(schema.Body, "\n")))
+	}
+}
+
+func groupAnchor(g api.ResourceGroup) string {
+	return parameterize(g.Title)
+}
+
+func resourceAnchor(g api.ResourceGroup, x *api.Resource) string {
+	s := parameterize(resourceTitle(x))
+
+	if g.Title != "" {
+		s = parameterize(g.Title) + "-" + s
+	}
+
+	return s
+}
+
+func resourceTitle(x *api.Resource) string {
+	if x.Title != "" {
+		return x.Title
+	}
+
+	return x.Href.Path
+}
+
+func transitionTitle(t *api.Transition) string {
+	if t.Title != "" {
+		return t.Title
+	}
+
+	return t.Method
+}
@@ -0,0 +1,50 @@
+package parser_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	snowboard "github.com/subosito/snowboard/parser"
+)
+
+func TestMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := snowboard.Markdown(&buf, renderFixture())
+	assert.Nil(t, err)
+
+	s := buf.String()
+	assert.Contains(t, s, "# Notes API\n")
+	assert.Contains(t, s, "- [Notes](#notes)")
+	assert.Contains(t, s, "    - [Retrieve a Note](#notes-note-retrieve-a-note)")
+	assert.Contains(t, s, "<a id=\"notes-note-retrieve-a-note\"></a>\n#### Retrieve a Note")
+	assert.Contains(t, s, "| `id` | number | yes | 1 |  |")
+	assert.Contains(t, s, "```json\n{\"id\": 1}\n```")
+}
+
+func TestMarkdownSplit(t *testing.T) {
+	ds := snowboard.MarkdownSplit(renderFixture())
+	assert.Len(t, ds, 2)
+	assert.Equal(t, "index.md", ds[0].Name)
+	assert.Contains(t, string(ds[0].Body), "[Retrieve a Note](notes.md#notes-note-retrieve-a-note)")
+	assert.Equal(t, "notes.md", ds[1].Name)
+	assert.Contains(t, string(ds[1].Body), "## Notes")
+}
+
+func TestAsciiDoc(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := snowboard.AsciiDoc(&buf, renderFixture())
+	assert.Nil(t, err)
+
+	s := buf.String()
+	assert.Contains(t, s, "= Notes API\n")
+	assert.Contains(t, s, "*** <<notes-note-retrieve-a-note,Retrieve a Note>>")
+	assert.Contains(t, s, "[[notes-note-retrieve-a-note]]\n==== Retrieve a Note")
+	assert.Contains(t, s, "|`+id+` |number |yes |1 |")
+	assert.Contains(t, s, "[source,json]\n----\n{\"id\": 1}\n----")
+
+	ds := snowboard.AsciiDocSplit(renderFixture())
+	assert.Contains(t, string(ds[0].Body), "xref:notes.adoc#notes[Notes]")
+}
@@ -0,0 +1,261 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/subosito/snowboard/api"
+)
+
+// ReadTraffic reads recorded requests of mock journal or HAR file, HAR is
+// detected by its top level "log" object
+func ReadTraffic(name string) ([]*MockLogEntry, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseTraffic(b)
+}
+
+// ParseTraffic reads recorded requests of mock journal or HAR content
+func ParseTraffic(b []byte) ([]*MockLogEntry, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+
+	if t, err := dec.Token(); err == nil && t == json.Delim('{') {
+		if t, err = dec.Token(); err == nil && t == "log" {
+			return ReadHAR(bytes.NewReader(b))
+		}
+	}
+
+	return ReadMockJournal(bytes.NewReader(b))
+}
+
+// ReadHAR reads entries of HTTP Archive
+func ReadHAR(r io.Reader) ([]*MockLogEntry, error) {
+	h := struct {
+		Log struct {
+			Entries []struct {
+				StartedDateTime string  `json:"startedDateTime"`
+				Time            float64 `json:"time"`
+				Request         struct {
+					Method      string      `json:"method"`
+					URL         string      `json:"url"`
+					HTTPVersion string      `json:"httpVersion"`
+					Headers     []harHeader `json:"headers"`
+					PostData    *struct {
+						MimeType string `json:"mimeType"`
+						Text     string `json:"text"`
+					} `json:"postData"`
+				} `json:"request"`
+				Response struct {
+					Status  int         `json:"status"`
+					Headers []harHeader `json:"headers"`
+					Content struct {
+						MimeType string `json:"mimeType"`
+						Text     string `json:"text"`
+						Encoding string `json:"encoding"`
+					} `json:"content"`
+				} `json:"response"`
+			} `json:"entries"`
+		} `json:"log"`
+	}{}
+
+	if err := json.NewDecoder(r).Decode(&h); err != nil {
+		return nil, fmt.Errorf("Unable to read HAR: %s", err)
+	}
+
+	xs := []*MockLogEntry{}
+
+	for _, x := range h.Log.Entries {
+		t, _ := time.Parse(time.RFC3339Nano, x.StartedDateTime)
+
+		e := &MockLogEntry{
+			Time:       t,
+			Method:     strings.ToUpper(x.Request.Method),
+			URL:        x.Request.URL,
+			Proto:      x.Request.HTTPVersion,
+			StatusCode: x.Response.Status,
+			Duration:   time.Duration(x.Time * float64(time.Millisecond)).String(),
+			Request:    &MockMessage{Headers: harHTTPHeader(x.Request.Headers)},
+			Response:   &MockMessage{Headers: harHTTPHeader(x.Response.Headers)},
+		}
+
+		if p := x.Request.PostData; p != nil {
+			e.Request.Body = p.Text
+
+			if e.Request.Headers.Get("Content-Type") == "" && p.MimeType != "" {
+				e.Request.Headers.Set("Content-Type", p.MimeType)
+			}
+		}
+
+		// base64 encoded bodies are binary, they can't be compared anyway
+		if c := x.Response.Content; c.Encoding == "" {
+			e.Response.Body = c.Text
+		}
+
+		if e.Response.Headers.Get("Content-Type") == "" && x.Response.Content.MimeType != "" {
+			e.Response.Headers.Set("Content-Type", x.Response.Content.MimeType)
+		}
+
+		xs = append(xs, e)
+	}
+
+	return xs, nil
+}
+
+func harHTTPHeader(hs []harHeader) http.Header {
+	h := http.Header{}
+
+	for _, x := range hs {
+		// HTTP/2 pseudo headers such as ":authority"
+		if strings.HasPrefix(x.Name, ":") {
+			continue
+		}
+
+		h.Add(x.Name, x.Value)
+	}
+
+	return h
+}
+
+// trafficAction is a documented transition along with its resource group and
+// resource
+type trafficAction struct {
+	group      *api.ResourceGroup
+	resource   *api.Resource
+	transition *api.Transition
+	method     string
+}
+
+// trafficRouter matches recorded requests to documented transitions by
+// method and URI template, choosing among transitions sharing a route by
+// their query parameters as mock server does
+type trafficRouter struct {
+	router   *mockRouter
+	actions  map[*MockTransaction]*trafficAction
+	basePath string
+}
+
+func newTrafficRouter(b *api.API) *trafficRouter {
+	tr := &trafficRouter{actions: map[*MockTransaction]*trafficAction{}}
+	ms := MockTransactions{}
+
+	for i := range b.ResourceGroups {
+		g := &b.ResourceGroups[i]
+
+		for _, r := range g.Resources {
+			for _, t := range r.Transitions {
+				seen := map[string]bool{}
+
+				for _, n := range t.Transactions {
+					if seen[n.Request.Method] {
+						continue
+					}
+
+					seen[n.Request.Method] = true
+
+					m := &MockTransaction{
+						Path:   routeKey(t.URL, b.Host()),
+						Method: n.Request.Method,
+						Query:  mockQuery(t.URL, t.Href.Parameters),
+					}
+
+					tr.actions[m] = &trafficAction{group: g, resource: r, transition: t, method: n.Request.Method}
+					ms = append(ms, m)
+				}
+			}
+		}
+	}
+
+	tr.router = ms.Router()
+
+	if u, err := url.Parse(b.Host()); err == nil {
+		tr.basePath = strings.TrimSuffix(u.Path, "/")
+	}
+
+	return tr
+}
+
+// match returns documented action of request along with its path parameters.
+// Paths prefixed by base path of HOST metadata match as well.
+func (tr *trafficRouter) match(method, rawurl string) (*trafficAction, map[string]string, bool) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	paths := []string{u.Path}
+	if tr.basePath != "" && strings.HasPrefix(u.Path, tr.basePath+"/") {
+		paths = append(paths, strings.TrimPrefix(u.Path, tr.basePath))
+	}
+
+	for _, p := range paths {
+		if m, params, ok := tr.router.lookup(method, p); ok {
+			t := selectTransaction(m.Transactions, u.Query(), func(*MockTransaction) bool { return true })
+			return tr.actions[t], params, true
+		}
+	}
+
+	return nil, nil, false
+}
+
+// controlEntry reports whether e is request to admin API or CORS preflight
+// of mock server rather than traffic of the mocked API
+func controlEntry(e *MockLogEntry) bool {
+	if u, err := url.Parse(e.URL); err == nil && strings.HasPrefix(u.Path, mockAdminPrefix) {
+		return true
+	}
+
+	return e.Method == http.MethodOptions && e.Request != nil && e.Request.Headers.Get("Origin") != "" && e.Request.Headers.Get("Access-Control-Request-Method") != ""
+}
+
+// gapSet aggregates requests matching no documented action
+type gapSet map[string]*CoverageGap
+
+func (gs gapSet) add(e *MockLogEntry) {
+	p := e.URL
+	if u, err := url.Parse(e.URL); err == nil {
+		p = u.Path
+	}
+
+	key := fmt.Sprintf("%s %s %d", e.Method, p, e.StatusCode)
+	if gs[key] == nil {
+		gs[key] = &CoverageGap{Method: e.Method, Path: p, StatusCode: e.StatusCode}
+	}
+
+	gs[key].Hits++
+}
+
+// list returns gaps ordered by path, method and status code
+func (gs gapSet) list() []CoverageGap {
+	xs := []CoverageGap{}
+
+	for _, x := range gs {
+		xs = append(xs, *x)
+	}
+
+	sort.Slice(xs, func(i, j int) bool {
+		a, b := xs[i], xs[j]
+
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+
+		return a.StatusCode < b.StatusCode
+	})
+
+	return xs
+}
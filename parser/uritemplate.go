@@ -0,0 +1,313 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+type uriOperator struct {
+	first    string
+	sep      string
+	named    bool
+	ifemp    string
+	reserved bool
+}
+
+var uriOperators = map[byte]uriOperator{
+	'+': {first: "", sep: ",", reserved: true},
+	'#': {first: "#", sep: ",", reserved: true},
+	'.': {first: ".", sep: "."},
+	'/': {first: "/", sep: "/"},
+	';': {first: ";", sep: ";", named: true},
+	'?': {first: "?", sep: "&", named: true, ifemp: "="},
+	'&': {first: "&", sep: "&", named: true, ifemp: "="},
+}
+
+var uriExpressionPattern = regexp.MustCompile(`\{([^}]*)\}`)
+
+// expandURI expands URI template as described on RFC 6570 using string values.
+// Undefined variables are omitted from the result.
+func expandURI(tpl string, vars map[string]string) string {
+	return uriExpressionPattern.ReplaceAllStringFunc(tpl, func(s string) string {
+		expr := s[1 : len(s)-1]
+		if expr == "" {
+			return ""
+		}
+
+		op, ok := uriOperators[expr[0]]
+		if ok {
+			expr = expr[1:]
+		} else {
+			op = uriOperator{sep: ","}
+		}
+
+		xs := []string{}
+
+		for _, spec := range strings.Split(expr, ",") {
+			name, prefix := uriVarSpec(spec)
+
+			v, ok := vars[name]
+			if !ok {
+				continue
+			}
+
+			if prefix > 0 && prefix < len(v) {
+				v = v[:prefix]
+			}
+
+			v = uriEncode(v, op.reserved)
+
+			switch {
+			case !op.named:
+				xs = append(xs, v)
+			case v == "":
+				xs = append(xs, name+op.ifemp)
+			default:
+				xs = append(xs, name+"="+v)
+			}
+		}
+
+		if len(xs) == 0 {
+			return ""
+		}
+
+		return op.first + strings.Join(xs, op.sep)
+	})
+}
+
+func uriVarSpec(spec string) (string, int) {
+	spec = strings.TrimSuffix(strings.TrimSpace(spec), "*")
+
+	if i := strings.Index(spec, ":"); i != -1 {
+		var n int
+		fmt.Sscanf(spec[i+1:], "%d", &n)
+		return spec[:i], n
+	}
+
+	return spec, 0
+}
+
+func uriEncode(s string, reserved bool) string {
+	var buf bytes.Buffer
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case isUnreserved(c):
+			buf.WriteByte(c)
+		case reserved && isReserved(c):
+			buf.WriteByte(c)
+		case reserved && c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			buf.WriteByte(c)
+		default:
+			fmt.Fprintf(&buf, "%%%02X", c)
+		}
+	}
+
+	return buf.String()
+}
+
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("-._~", c) != -1
+}
+
+func isReserved(c byte) bool {
+	return strings.IndexByte(":/?#[]@!$&'()*+,;=", c) != -1
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+var (
+	uriQueryExpressionPattern  = regexp.MustCompile(`\{[?&#][^}]*\}`)
+	uriSimpleExpressionPattern = regexp.MustCompile(`\{(\w+)\}`)
+)
+
+// routeKey returns path part of URI template used as routing key. Query and
+// fragment expressions are dropped. Templates having only simple expressions
+// are converted into router patterns, e.g. "/notes/{id}" into "/notes/:id",
+// others keep their expressions and are matched by uriMatcher.
+func routeKey(u, host string) string {
+	if host != "" {
+		u = strings.Replace(u, host, "", 1)
+	}
+
+	u = uriQueryExpressionPattern.ReplaceAllString(u, "")
+
+	if i := strings.IndexAny(u, "?#"); i != -1 {
+		u = u[:i]
+	}
+
+	if strings.Contains(uriSimpleExpressionPattern.ReplaceAllString(u, ""), "{") {
+		return path.Join("/", u)
+	}
+
+	return urlPath(path.Join("/", uriSimpleExpressionPattern.ReplaceAllString(u, ":${1}")))
+}
+
+// uriVars returns names of all variables of template
+func uriVars(u string) []string {
+	xs := []string{}
+
+	for _, ms := range uriExpressionPattern.FindAllStringSubmatch(u, -1) {
+		expr := ms[1]
+		if expr == "" {
+			continue
+		}
+
+		if _, ok := uriOperators[expr[0]]; ok {
+			expr = expr[1:]
+		}
+
+		for _, spec := range strings.Split(expr, ",") {
+			name, _ := uriVarSpec(spec)
+			xs = append(xs, name)
+		}
+	}
+
+	return xs
+}
+
+// uriQueryVars returns names of variables of query expressions of template
+func uriQueryVars(u string) []string {
+	xs := []string{}
+
+	for _, ms := range uriExpressionPattern.FindAllStringSubmatch(u, -1) {
+		expr := ms[1]
+		if expr == "" || (expr[0] != '?' && expr[0] != '&') {
+			continue
+		}
+
+		for _, spec := range strings.Split(expr[1:], ",") {
+			name, _ := uriVarSpec(spec)
+			xs = append(xs, name)
+		}
+	}
+
+	return xs
+}
+
+// uriMatcher matches request paths against path part of URI template
+type uriMatcher struct {
+	re   *regexp.Regexp
+	vars []uriCapture
+}
+
+type uriCapture struct {
+	name    string
+	op      byte
+	explode bool
+}
+
+// compileURIMatcher compiles routing key returned by routeKey into matcher
+// supporting every RFC 6570 operator
+func compileURIMatcher(key string) (*uriMatcher, error) {
+	var buf bytes.Buffer
+	m := &uriMatcher{}
+	last := 0
+
+	buf.WriteString("^")
+
+	for _, loc := range uriExpressionPattern.FindAllStringSubmatchIndex(key, -1) {
+		buf.WriteString(regexp.QuoteMeta(key[last:loc[0]]))
+		last = loc[1]
+
+		expr := key[loc[2]:loc[3]]
+		if expr == "" {
+			continue
+		}
+
+		op := byte(0)
+		if _, ok := uriOperators[expr[0]]; ok {
+			op = expr[0]
+			expr = expr[1:]
+		}
+
+		for i, spec := range strings.Split(expr, ",") {
+			name, prefix := uriVarSpec(spec)
+			explode := strings.HasSuffix(strings.TrimSpace(spec), "*")
+
+			class := "[^/?#,]"
+			switch op {
+			case '+':
+				class = "[^?#,]"
+			case '.':
+				class = `[^/?#.]`
+			case ';':
+				class = "[^/?#;]"
+			case '/':
+				class = "[^/?#]"
+			}
+
+			// lazy, so that following expressions such as "{.format}"
+			// get their part
+			rep := "*?"
+			if prefix > 0 {
+				rep = fmt.Sprintf("{0,%d}?", prefix)
+			}
+
+			v := "(" + class + rep + ")"
+
+			switch {
+			case explode && op == '/':
+				v = "((?:/[^/?#]*)*)"
+			case explode && op == '.':
+				v = `((?:\.[^/?#.]*)*)`
+			case explode && op == '+':
+				v = "([^?#]*)"
+			case explode && op == 0:
+				v = "([^/?#]*)"
+			case op == '.':
+				v = `(?:\.` + v + ")?"
+			case op == '/':
+				v = "(?:/" + v + ")?"
+			case op == ';':
+				v = "(?:;" + regexp.QuoteMeta(name) + "(?:=" + v + ")?)?"
+			case i > 0:
+				v = "(?:," + v + ")?"
+			}
+
+			buf.WriteString(v)
+			m.vars = append(m.vars, uriCapture{name: name, op: op, explode: explode})
+		}
+	}
+
+	buf.WriteString(regexp.QuoteMeta(key[last:]))
+	buf.WriteString("$")
+
+	re, err := regexp.Compile(buf.String())
+	if err != nil {
+		return nil, err
+	}
+
+	m.re = re
+	return m, nil
+}
+
+// match returns variables when path matches the template. Path is decoded
+// already, so variables are not unescaped again.
+func (m *uriMatcher) match(p string) (map[string]string, bool) {
+	ms := m.re.FindStringSubmatch(p)
+	if ms == nil {
+		return nil, false
+	}
+
+	vars := map[string]string{}
+
+	for i, c := range m.vars {
+		v := ms[i+1]
+
+		if c.explode && (c.op == '/' || c.op == '.') {
+			v = strings.TrimPrefix(v, string(c.op))
+		}
+
+		vars[c.name] = v
+	}
+
+	return vars, true
+}
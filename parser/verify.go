@@ -0,0 +1,300 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/subosito/snowboard/api"
+)
+
+// TrafficReport is the result of verifying recorded traffic against
+// blueprint. Requests matching no documented action are listed in Gaps.
+type TrafficReport struct {
+	Total   int             `json:"total"`
+	Passed  int             `json:"passed"`
+	Failed  int             `json:"failed"`
+	Results []TrafficResult `json:"results"`
+	Gaps    []CoverageGap   `json:"gaps"`
+}
+
+// TrafficResult is a recorded request matched to documented action along
+// with its contract violations
+type TrafficResult struct {
+	Method     string   `json:"method"`
+	URL        string   `json:"url"`
+	StatusCode int      `json:"status"`
+	Action     string   `json:"action"`
+	Problems   []string `json:"problems,omitempty"`
+}
+
+// OK reports whether traffic conforms to blueprint
+func (r *TrafficReport) OK() bool {
+	return r.Failed == 0 && len(r.Gaps) == 0
+}
+
+// VerifyTraffic matches each recorded request to documented transaction by
+// method, URI template and status code, then checks content type, headers
+// and bodies against documented schemas, or shape of examples when schema is
+// missing
+func VerifyTraffic(b *api.API, entries []*MockLogEntry) *TrafficReport {
+	tr := newTrafficRouter(b)
+	r := &TrafficReport{Results: []TrafficResult{}}
+	gaps := gapSet{}
+
+	for _, e := range entries {
+		// connections dropped by chaos have no response
+		if e.StatusCode == 0 {
+			continue
+		}
+
+		a, _, ok := tr.match(e.Method, e.URL)
+		if !ok {
+			if !controlEntry(e) {
+				gaps.add(e)
+			}
+
+			continue
+		}
+
+		x := TrafficResult{
+			Method:     e.Method,
+			URL:        e.URL,
+			StatusCode: e.StatusCode,
+			Action:     a.method + " " + a.transition.URL,
+			Problems:   verifyEntry(a, e),
+		}
+
+		if a.transition.Title != "" {
+			x.Action = a.transition.Title + " (" + x.Action + ")"
+		}
+
+		r.Total++
+
+		if len(x.Problems) == 0 {
+			r.Passed++
+		} else {
+			r.Failed++
+		}
+
+		r.Results = append(r.Results, x)
+	}
+
+	r.Gaps = gaps.list()
+
+	return r
+}
+
+// verifyEntry returns problems of e against the best fitting transaction of
+// action a
+func verifyEntry(a *trafficAction, e *MockLogEntry) []string {
+	method := e.Method
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+
+	var best []string
+	found := false
+
+	for _, n := range a.transition.Transactions {
+		if n.Request.Method != method || n.Response.StatusCode != e.StatusCode {
+			continue
+		}
+
+		ps := verifyTransaction(n, e)
+		if !found || len(ps) < len(best) {
+			best = ps
+			found = true
+		}
+	}
+
+	if !found {
+		return []string{"undocumented status " + strconv.Itoa(e.StatusCode)}
+	}
+
+	return best
+}
+
+func verifyTransaction(n api.Transaction, e *MockLogEntry) []string {
+	ps := []string{}
+
+	if q := e.Request; q != nil && q.Body != "" && !q.Truncated {
+		ps = append(ps, verifyBody("request body", n.Request.Schema.Body, n.Request.Body.Body, q)...)
+	}
+
+	p := e.Response
+	if p == nil {
+		return ps
+	}
+
+	documented := n.Response.Body.ContentType
+	for _, h := range n.Response.Headers {
+		if strings.EqualFold(h.Key, "Content-Type") && documented == "" {
+			documented = h.Value
+		}
+	}
+
+	if actual := p.Headers.Get("Content-Type"); documented != "" && p.Body != "" && !sameMediaType(documented, actual) {
+		ps = append(ps, fmt.Sprintf("content type: expected %s, got %s", documented, actual))
+	}
+
+	for _, h := range n.Response.Headers {
+		switch http.CanonicalHeaderKey(h.Key) {
+		case "Content-Type", "Content-Length", "Transfer-Encoding", "Date", "Connection":
+			continue
+		}
+
+		if _, ok := p.Headers[http.CanonicalHeaderKey(h.Key)]; !ok {
+			ps = append(ps, "missing header "+http.CanonicalHeaderKey(h.Key))
+		}
+	}
+
+	if p.Body != "" && !p.Truncated && e.Method != http.MethodHead {
+		ps = append(ps, verifyBody("response body", n.Response.Schema.Body, n.Response.Body.Body, p)...)
+	}
+
+	return ps
+}
+
+// verifyBody validates JSON body of m against schema, or against shape of
+// example when schema is missing
+func verifyBody(what, schema, example string, m *MockMessage) []string {
+	if alias(m.Headers.Get("Content-Type")) != "json" {
+		return nil
+	}
+
+	if strings.TrimSpace(schema) != "" {
+		errs, err := validateJSON(schema, m.Body)
+		if err != nil {
+			return []string{what + ": " + err.Error()}
+		}
+
+		return prefixAll(what+": ", errs)
+	}
+
+	var x, y interface{}
+
+	if json.Unmarshal([]byte(example), &x) != nil {
+		return nil
+	}
+
+	if err := json.Unmarshal([]byte(m.Body), &y); err != nil {
+		return []string{what + ": invalid JSON"}
+	}
+
+	return prefixAll(what+": ", compareShape(x, y, "$"))
+}
+
+// compareShape reports properties of example x missing from v, or having
+// another type
+func compareShape(x, v interface{}, at string) []string {
+	if x == nil || v == nil {
+		return nil
+	}
+
+	kx, kv := jsonKind(x), jsonKind(v)
+	if kx == "integer" {
+		kx = "number"
+	}
+
+	if kv == "integer" {
+		kv = "number"
+	}
+
+	if kx != kv {
+		return []string{fmt.Sprintf("%s: expected %s, got %s", at, kx, kv)}
+	}
+
+	errs := []string{}
+
+	switch a := x.(type) {
+	case map[string]interface{}:
+		b := v.(map[string]interface{})
+
+		keys := []string{}
+		for k := range a {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			y, ok := b[k]
+			if !ok {
+				errs = append(errs, fmt.Sprintf("%s: missing property %q", at, k))
+				continue
+			}
+
+			errs = append(errs, compareShape(a[k], y, at+"."+k)...)
+		}
+	case []interface{}:
+		b := v.([]interface{})
+
+		if len(a) > 0 {
+			for i, y := range b {
+				errs = append(errs, compareShape(a[0], y, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+	}
+
+	return errs
+}
+
+func sameMediaType(a, b string) bool {
+	x, _, err := mime.ParseMediaType(a)
+	if err != nil {
+		return strings.EqualFold(a, b)
+	}
+
+	y, _, err := mime.ParseMediaType(b)
+	if err != nil {
+		return false
+	}
+
+	return x == y
+}
+
+func prefixAll(prefix string, xs []string) []string {
+	ys := []string{}
+
+	for _, x := range xs {
+		ys = append(ys, prefix+x)
+	}
+
+	return ys
+}
+
+// TrafficText writes failed requests and spec gaps of report as plain text
+func TrafficText(w io.Writer, r *TrafficReport) error {
+	for _, x := range r.Results {
+		if len(x.Problems) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "%s %s %d\n  matched %s\n", x.Method, x.URL, x.StatusCode, x.Action)
+
+		for _, p := range x.Problems {
+			fmt.Fprintf(w, "  - %s\n", p)
+		}
+
+		fmt.Fprintln(w)
+	}
+
+	if len(r.Gaps) > 0 {
+		fmt.Fprintln(w, "Undocumented endpoints:")
+
+		for _, x := range r.Gaps {
+			fmt.Fprintf(w, "  %s %s (%d, %d requests)\n", x.Method, x.Path, x.StatusCode, x.Hits)
+		}
+
+		fmt.Fprintln(w)
+	}
+
+	_, err := fmt.Fprintf(w, "%d requests verified: %d passed, %d failed, %d undocumented endpoints\n", r.Total, r.Passed, r.Failed, len(r.Gaps))
+	return err
+}
@@ -0,0 +1,112 @@
+package parser_test
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/subosito/snowboard/api"
+	snowboard "github.com/subosito/snowboard/parser"
+)
+
+func verifyFixture() *api.API {
+	b := renderFixture()
+	b.Metadata = []api.Metadata{{Key: "HOST", Value: "https://api.example.com/v1"}}
+
+	t := b.ResourceGroups[0].Resources[0].Transitions[0]
+	t.URL = "/notes/{id}"
+	t.Transactions[0].Response.Headers = append(t.Transactions[0].Response.Headers, api.Header{Key: "ETag", Value: `"1"`})
+	t.Transactions[0].Response.Schema = api.Asset{Body: `{
+		"type": "object",
+		"properties": {
+			"id": {"type": "number"},
+			"tags": {"type": "array", "items": {"type": "string", "enum": ["a", "b"]}}
+		},
+		"required": ["id"],
+		"additionalProperties": false
+	}`}
+
+	r := b.ResourceGroups[0].Resources[0]
+	r.Transitions = append(r.Transitions, &api.Transition{
+		Method: "POST",
+		URL:    "/notes",
+		Transactions: []api.Transaction{
+			{
+				Request:  api.Request{Method: "POST", Body: api.Asset{ContentType: "application/json", Body: `{"title": "a"}`}},
+				Response: api.Response{StatusCode: 201, Body: api.Asset{ContentType: "application/json", Body: `{"id": 1, "title": "a"}`}},
+			},
+		},
+	})
+
+	return b
+}
+
+func verifyEntry(method, u string, status int, reqBody string, h http.Header, body string) *snowboard.MockLogEntry {
+	return &snowboard.MockLogEntry{
+		Method:     method,
+		URL:        u,
+		StatusCode: status,
+		Request:    &snowboard.MockMessage{Headers: http.Header{"Content-Type": {"application/json"}}, Body: reqBody},
+		Response:   &snowboard.MockMessage{Headers: h, Body: body},
+	}
+}
+
+func TestVerifyTraffic(t *testing.T) {
+	json := http.Header{"Content-Type": {"application/json; charset=utf-8"}, "Etag": {`"1"`}}
+
+	entries := []*snowboard.MockLogEntry{
+		verifyEntry("GET", "https://api.example.com/v1/notes/1", 200, "", json, `{"id": 1, "tags": ["a"]}`),
+		verifyEntry("GET", "https://api.example.com/v1/notes/2", 200, "", http.Header{"Content-Type": {"text/html"}}, `<p>1</p>`),
+		verifyEntry("GET", "https://api.example.com/v1/notes/3", 200, "", json, `{"id": "3", "tags": ["c"], "extra": true}`),
+		verifyEntry("GET", "https://api.example.com/v1/notes/4", 500, "", json, `{}`),
+		verifyEntry("POST", "https://api.example.com/v1/notes", 201, `{"title": 1}`, json, `{"id": 5}`),
+		verifyEntry("DELETE", "https://api.example.com/v1/notes/1", 204, "", http.Header{}, ""),
+	}
+
+	r := snowboard.VerifyTraffic(verifyFixture(), entries)
+	assert.Equal(t, 5, r.Total)
+	assert.Equal(t, 1, r.Passed)
+	assert.Equal(t, 4, r.Failed)
+	assert.False(t, r.OK())
+
+	assert.Equal(t, "Retrieve a Note (GET /notes/{id})", r.Results[0].Action)
+	assert.Empty(t, r.Results[0].Problems)
+	assert.Equal(t, []string{"content type: expected application/json, got text/html", "missing header Etag"}, r.Results[1].Problems)
+	assert.Equal(t, []string{
+		`response body: $: undocumented property "extra"`,
+		`response body: $.id: expected number, got string`,
+		`response body: $.tags[0]: "c" is not one of allowed values`,
+	}, r.Results[2].Problems)
+	assert.Equal(t, []string{"undocumented status 500"}, r.Results[3].Problems)
+	assert.Equal(t, []string{
+		"request body: $.title: expected string, got number",
+		`response body: $: missing property "title"`,
+	}, r.Results[4].Problems)
+	assert.Equal(t, []snowboard.CoverageGap{{Method: "DELETE", Path: "/v1/notes/1", StatusCode: 204, Hits: 1}}, r.Gaps)
+
+	var buf bytes.Buffer
+
+	assert.Nil(t, snowboard.TrafficText(&buf, r))
+	assert.Contains(t, buf.String(), "  - undocumented status 500\n")
+	assert.Contains(t, buf.String(), "5 requests verified: 1 passed, 4 failed, 1 undocumented endpoints\n")
+}
+
+func TestVerifyTraffic_query(t *testing.T) {
+	json := http.Header{"Content-Type": {"application/json"}}
+
+	entries := []*snowboard.MockLogEntry{
+		verifyEntry("GET", "http://127.0.0.1:8087/notes?page=2", 200, "", http.Header{}, ""),
+		verifyEntry("GET", "http://127.0.0.1:8087/notes?q=a", 200, "", json, `[{"id": 2}]`),
+		verifyEntry("GET", "http://127.0.0.1:8087/notes?q=b", 200, "", json, `{"id": 2}`),
+	}
+
+	r := snowboard.VerifyTraffic(queryFixture(), entries)
+	assert.Equal(t, 3, r.Total)
+	assert.Equal(t, 2, r.Passed)
+
+	assert.Equal(t, "List Notes (GET /notes{?page})", r.Results[0].Action)
+	assert.Equal(t, "Search Notes (GET /notes{?q})", r.Results[1].Action)
+	assert.Equal(t, "Search Notes (GET /notes{?q})", r.Results[2].Action)
+	assert.NotEmpty(t, r.Results[2].Problems)
+}
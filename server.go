@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/urfave/cli"
+)
+
+// tlsFlags are shared by commands running HTTP server
+var tlsFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "tls-cert",
+		Usage: "TLS certificate file, enables HTTPS and HTTP/2",
+	},
+	cli.StringFlag{
+		Name:  "tls-key",
+		Usage: "TLS private key file",
+	},
+	cli.BoolFlag{
+		Name:  "tls-self-signed",
+		Usage: "Enable HTTPS and HTTP/2 using generated self-signed certificate for localhost",
+	},
+}
+
+// serverOptions configures HTTP servers of mock and html commands
+type serverOptions struct {
+	certFile   string
+	keyFile    string
+	selfSigned bool
+}
+
+func newServerOptions(c *cli.Context) (serverOptions, error) {
+	o := serverOptions{
+		certFile:   c.String("tls-cert"),
+		keyFile:    c.String("tls-key"),
+		selfSigned: c.Bool("tls-self-signed"),
+	}
+
+	if (o.certFile == "") != (o.keyFile == "") {
+		return o, errors.New("Both --tls-cert and --tls-key are required")
+	}
+
+	if o.selfSigned && o.certFile != "" {
+		return o, errors.New("Use either --tls-self-signed or --tls-cert and --tls-key")
+	}
+
+	return o, nil
+}
+
+func (o serverOptions) tls() bool {
+	return o.selfSigned || o.certFile != ""
+}
+
+// url returns base URL of server listening on bind
+func (o serverOptions) url(bind string) string {
+	if o.tls() {
+		return "https://" + bind
+	}
+
+	return "http://" + bind
+}
+
+// shutdownTimeout is how long in-flight requests are waited for on shutdown
+const shutdownTimeout = 10 * time.Second
+
+// listenAndServe serves h on bind until interrupted, then shuts the server
+// down gracefully. Write timeout is left unset, as mock latency injection may
+// delay responses on purpose.
+func listenAndServe(c *cli.Context, bind string, h http.Handler, o serverOptions) error {
+	srv := &http.Server{
+		Addr:              bind,
+		Handler:           h,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       time.Minute,
+		IdleTimeout:       2 * time.Minute,
+	}
+
+	if o.selfSigned {
+		cert, err := selfSignedCertificate()
+		if err != nil {
+			return err
+		}
+
+		srv.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+
+		<-sig
+		fmt.Fprintln(c.App.Writer, "Shutting down...")
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		done <- srv.Shutdown(ctx)
+	}()
+
+	var err error
+
+	if o.tls() {
+		err = srv.ListenAndServeTLS(o.certFile, o.keyFile)
+	} else {
+		err = srv.ListenAndServe()
+	}
+
+	if err != http.ErrServerClosed {
+		return err
+	}
+
+	return <-done
+}
+
+// selfSignedCertificate generates in-memory certificate for localhost
+func selfSignedCertificate() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	now := time.Now()
+	tpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"Snowboard"}, CommonName: "localhost"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
@@ -0,0 +1,71 @@
+#!/usr/bin/env bash
+
+# Mirrors third-party theme assets into templates/assets so they can be
+# embedded into the binary and inlined by `snowboard html --inline`.
+#
+# Every line of assets list is "<sha256> <url>". Downloads, and copies
+# already mirrored, are verified against the pinned sum. Run with --pin to
+# download assets listed without sum and pin what was downloaded; review the
+# resulting diff before committing it.
+
+PIN=0
+
+if [[ "$1" == "--pin" ]]; then
+  PIN=1
+  shift
+fi
+
+ASSETS_LIST=${1:-tools/assets.txt}
+ASSETS_DIR=${2:-templates/assets}
+
+sha256() {
+  if command -v sha256sum >/dev/null; then
+    sha256sum "$1" | cut -d' ' -f1
+  else
+    shasum -a 256 "$1" | cut -d' ' -f1
+  fi
+}
+
+pinned=$(mktemp)
+trap 'rm -f "$pinned" "$pinned.part"' EXIT
+
+while read -r sum url; do
+  if [[ -z "$sum" || "$sum" == \#* ]]; then
+    echo "$sum${url:+ $url}" >> "$pinned"
+    continue
+  fi
+
+  if [[ -z "$url" ]]; then
+    url=$sum
+    sum=""
+  fi
+
+  if [[ -z "$sum" && $PIN -eq 0 ]]; then
+    echo "No sha256 pinned for ${url}, run 'make assets-pin' and review the result" >&2
+    exit 1
+  fi
+
+  target="${ASSETS_DIR}/${url#*://}"
+
+  if [[ ! -f "$target" ]]; then
+    echo "Fetching ${url}"
+    curl -sSfL --create-dirs -o "$pinned.part" "$url" || exit 1
+  else
+    cp "$target" "$pinned.part"
+  fi
+
+  actual=$(sha256 "$pinned.part")
+
+  if [[ -n "$sum" && "$actual" != "$sum" ]]; then
+    echo "Checksum mismatch of ${url}: expected ${sum}, got ${actual}" >&2
+    exit 1
+  fi
+
+  mkdir -p "$(dirname "$target")"
+  mv "$pinned.part" "$target"
+  echo "${actual} ${url}" >> "$pinned"
+done < "$ASSETS_LIST"
+
+if [[ $PIN -eq 1 ]]; then
+  cp "$pinned" "$ASSETS_LIST"
+fi